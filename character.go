@@ -1,26 +1,24 @@
 package main
 
 import (
+	"image"
 	"log"
+	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/mroobit/untitled-sidescroller/sim"
 )
 
 var (
 	spriteSheet *ebiten.Image
 
-	playerView       *Viewer
-	playerChar       *Character
-	playerCharHeight = 48
-	playerCharWidth  = 48
+	playerCharHeight = sim.PlayerHeight
+	playerCharWidth  = sim.PlayerWidth
 
-	worldPlayerView *Viewer
-	worldPlayer     *WorldChar
 	worldCharWidth  = 48
 	worldCharHeight = 48
 
 	defaultFrame = 2
-	currentFrame = defaultFrame
 	frameCount   = 12
 )
 
@@ -32,22 +30,6 @@ type Viewer struct {
 	height int
 }
 
-// Character describes the player character's state
-type Character struct {
-	name      string
-	sprite    *ebiten.Image
-	view      *Viewer
-	facing    int
-	xCoord    int
-	yCoord    int
-	yVelo     int
-	active    bool
-	status    string
-	hpCurrent int
-	hpTotal   int
-	lives     int
-}
-
 // WorldChar describes the player navigation avatar on the main screen
 type WorldChar struct { // add to Character struct
 	sprite    *ebiten.Image
@@ -55,17 +37,10 @@ type WorldChar struct { // add to Character struct
 	direction string
 	xCoord    int
 	yCoord    int
-}
 
-func (c *Character) setLocation(x, y int) {
-	log.Printf("Resetting x,y coordinates")
-	c.xCoord = x
-	c.yCoord = y
-}
-func (c *Character) resetView() {
-	log.Printf("Resetting viewer")
-	c.view.xCoord = 0
-	c.view.yCoord = winHeight - levelHeight
+	node        string        // MapNode the avatar is standing on or last left, in path navigation
+	destination string        // MapNode at the end of route
+	route       []image.Point // remaining points to walk, in World image coordinates
 }
 
 // NewViewer creates new Viewer (screen offset)
@@ -80,26 +55,6 @@ func NewViewer() *Viewer {
 	return viewer
 }
 
-// NewCharacter creates new player character
-func NewCharacter(name string, sprite *ebiten.Image, view *Viewer, hp int) *Character {
-	log.Printf("Creating new character %s", name)
-	character := &Character{
-		name:      name,
-		sprite:    sprite,
-		view:      view,
-		facing:    0,
-		xCoord:    20,
-		yCoord:    380,
-		yVelo:     gravity,
-		active:    false,
-		status:    "ground",
-		hpCurrent: hp,
-		hpTotal:   hp,
-		lives:     4,
-	}
-	return character
-}
-
 // NewWorldChar creates new player navigation avatar
 func NewWorldChar(sprite *ebiten.Image, view *Viewer) *WorldChar {
 	log.Printf("Creating new world-navigation player character")
@@ -115,102 +70,97 @@ func NewWorldChar(sprite *ebiten.Image, view *Viewer) *WorldChar {
 	return wc
 }
 
-func (c *Character) moveRight() {
-	// only impact c
-	playerChar.facing = 0
+func (w *WorldChar) navRight(radiusCheck float64, p *Planet) {
+	w.direction = "right"
 	switch {
-	case playerChar.view.xCoord == 0 && playerChar.xCoord < 290:
-		playerChar.xCoord += 5
-	case playerChar.view.xCoord == -200 && playerChar.xCoord < 530:
-		playerChar.xCoord += 5
-	case playerChar.view.xCoord > -200:
-		playerChar.view.xCoord -= 5
-	}
-	playerCharSide := (playerChar.xCoord - playerChar.view.xCoord + playerCharWidth + 1) / 50
-	playerCharTop := (playerChar.yCoord - playerChar.view.yCoord) / 50
-	if levelMap[0][playerCharTop*tileXCount+playerCharSide] == 1 /* || levelMap[0][playerCharBase*tileXCount+playerCharSide] == 1*/ {
-		playerChar.xCoord -= 5
+	case w.view.xCoord == 0 && w.xCoord < 290:
+		w.xCoord += 5
+	case w.view.xCoord == p.ViewMinX && radiusCheck+50 < p.Radius: // w.xCoord < 500: // but actually, the arc of the circle
+		w.xCoord += 5
+	case w.view.xCoord > p.ViewMinX:
+		w.view.xCoord -= 5
 	}
-}
 
-func (c *Character) moveLeft() {
-	playerChar.facing = playerCharHeight
+}
+func (w *WorldChar) navLeft(radiusCheck float64, p *Planet) {
+	w.direction = "left"
 	switch {
-	case playerChar.view.xCoord == -200 && playerChar.xCoord > 290:
-		playerChar.xCoord -= 5
-	case playerChar.view.xCoord == 0 && playerChar.xCoord > 40:
-		playerChar.xCoord -= 5
-	case playerChar.view.xCoord < 0:
-		playerChar.view.xCoord += 5
+	case w.view.xCoord == p.ViewMinX && w.xCoord > 290:
+		w.xCoord -= 5
+	case w.view.xCoord == 0 && radiusCheck < p.Radius: // w.xCoord < 500: // but actually, the arc of the circle
+		w.xCoord -= 5
+	case w.view.xCoord < 0:
+		w.view.xCoord += 5
 	}
-	playerCharSide := (playerChar.xCoord - playerChar.view.xCoord) / 50
-	playerCharTop := (playerChar.yCoord - playerChar.view.yCoord) / 50
-	if levelMap[0][playerCharTop*tileXCount+playerCharSide] == 1 {
-		playerChar.xCoord += 5
+}
+func (w *WorldChar) navUp(radiusCheck float64, p *Planet) {
+	w.direction = "up"
+	switch {
+	case w.view.yCoord == p.ViewMinY && w.yCoord > 230:
+		w.yCoord -= 5
+	case w.view.yCoord == 0 && radiusCheck < p.Radius:
+		w.yCoord -= 5
+	case w.view.yCoord < 0:
+		w.view.yCoord += 5
 	}
 }
-
-func (c *Character) jump(duration int) { // strength is keypress duration
+func (w *WorldChar) navDown(radiusCheck float64, p *Planet) {
+	w.direction = "down"
 	switch {
-	case c.status == "ground" && duration == 1:
-		c.status = "jump"
-		c.yVelo = -gravity
-		//		c.yVelo = -10
-		//	case c.status == "jump" && duration == 2:
-		//		c.yVelo += -6
-		//	case c.status == "jump" && duration == 3:
-		//		c.yVelo += -4
+	case w.view.yCoord == 0 && w.yCoord < 250:
+		w.yCoord += 5
+	case w.view.yCoord == p.ViewMinY && radiusCheck+50 < p.Radius:
+		w.yCoord += 5
+	case w.view.yCoord > p.ViewMinY:
+		w.view.yCoord -= 5
 	}
 }
 
-func (c *Character) death() {
-	c.hpCurrent = 0
-	c.lives--
-	c.status = "dying"
+// mapLocation gives the avatar's location in World image coordinates
+func (w *WorldChar) mapLocation() (int, int) {
+	return w.xCoord - w.view.xCoord, w.yCoord - w.view.yCoord
 }
 
-func (w *WorldChar) navRight(radiusCheck float64) {
-	worldPlayer.direction = "right"
-	switch {
-	case worldPlayer.view.xCoord == 0 && worldPlayer.xCoord < 290:
-		worldPlayer.xCoord += 5
-	case worldPlayer.view.xCoord == -400 && radiusCheck+50 < radius: // worldPlayer.xCoord < 500: // but actually, the arc of the circle
-		worldPlayer.xCoord += 5
-	case worldPlayer.view.xCoord > -400:
-		worldPlayer.view.xCoord -= 5
-	}
+// setMapLocation places the avatar at World image coordinates, centering the view on it as far as p's map allows
+func (w *WorldChar) setMapLocation(p *Planet, x, y int) {
+	w.view.xCoord = clamp((winWidth-worldCharWidth)/2-x, p.ViewMinX, 0)
+	w.view.yCoord = clamp((winHeight-worldCharHeight)/2-y, p.ViewMinY, 0)
+	w.xCoord = x + w.view.xCoord
+	w.yCoord = y + w.view.yCoord
+}
 
+func (w *WorldChar) walking() bool {
+	return len(w.route) > 0
 }
-func (w *WorldChar) navLeft(radiusCheck float64) {
-	worldPlayer.direction = "left"
-	switch {
-	case worldPlayer.view.xCoord == -400 && worldPlayer.xCoord > 290:
-		worldPlayer.xCoord -= 5
-	case worldPlayer.view.xCoord == 0 && radiusCheck < radius: // worldPlayer.xCoord < 500: // but actually, the arc of the circle
-		worldPlayer.xCoord -= 5
-	case worldPlayer.view.xCoord < 0:
-		worldPlayer.view.xCoord += 5
+
+// walk moves the avatar one step along its route on p, facing the direction of travel
+func (w *WorldChar) walk(p *Planet) {
+	x, y := w.mapLocation()
+	target := w.route[0]
+	dist := math.Hypot(float64(target.X-x), float64(target.Y-y))
+	if dist <= walkSpeed {
+		w.setMapLocation(p, target.X, target.Y)
+		w.route = w.route[1:]
+		if len(w.route) == 0 {
+			log.Printf("Arrived at %s", w.destination)
+			w.node = w.destination
+			w.destination = ""
+		}
+		return
 	}
+	w.direction = heading(image.Pt(x, y), target)
+	step := walkSpeed / dist
+	w.setMapLocation(p, x+int(math.Round(float64(target.X-x)*step)), y+int(math.Round(float64(target.Y-y)*step)))
 }
-func (w *WorldChar) navUp(radiusCheck float64) {
-	worldPlayer.direction = "up"
-	switch {
-	case worldPlayer.view.yCoord == -520 && worldPlayer.yCoord > 230:
-		worldPlayer.yCoord -= 5
-	case worldPlayer.view.yCoord == 0 && radiusCheck < radius:
-		worldPlayer.yCoord -= 5
-	case worldPlayer.view.yCoord < 0:
-		worldPlayer.view.yCoord += 5
+
+// frame gives the sprite sheet area for the avatar's direction, animated while walking
+func (w *WorldChar) frame(count int) image.Rectangle {
+	cx, cy := defaultFrame*worldCharWidth, 0
+	if w.walking() {
+		cx = (count / 5) % frameCount * worldCharWidth
 	}
-}
-func (w *WorldChar) navDown(radiusCheck float64) {
-	worldPlayer.direction = "down"
-	switch {
-	case worldPlayer.view.yCoord == 0 && worldPlayer.yCoord < 250:
-		worldPlayer.yCoord += 5
-	case worldPlayer.view.yCoord == -520 && radiusCheck+50 < radius:
-		worldPlayer.yCoord += 5
-	case worldPlayer.view.yCoord > -520:
-		worldPlayer.view.yCoord -= 5
+	if w.direction == "left" {
+		cy = worldCharHeight
 	}
+	return image.Rect(cx, cy, cx+worldCharWidth, cy+worldCharHeight)
 }
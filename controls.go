@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/tinne26/etxt"
+)
+
+// keyCaptureTicks is how long KeyCapture waits for a key before giving up, so that every key, Escape included, can be bound
+const keyCaptureTicks = 300
+
+// KeyCapture is a Game State that waits for a key to bind to an action; waiting it out, right-click or the gamepad's Back cancels
+type KeyCapture struct {
+	stateHooks
+	action Action
+	err    error
+	ticks  int // ticks waited since opening, or since the last conflict
+}
+
+// NewKeyCapture creates a KeyCapture for action
+func NewKeyCapture(action Action) *KeyCapture {
+	return &KeyCapture{action: action}
+}
+
+// Update binds the first key pressed, unless another action used alongside this one already has it
+func (k *KeyCapture) Update(g *Game) error {
+	k.ticks++
+	if k.ticks >= keyCaptureTicks || gamepadJustPressed(actionBack) || backPressed() {
+		log.Printf("Rebinding %s cancelled", k.action)
+		g.pop()
+		return nil
+	}
+	for _, key := range inpututil.AppendPressedKeys(nil) {
+		if !inpututil.IsKeyJustPressed(key) {
+			continue
+		}
+		if k.err = rebind(settings.Bindings, k.action, key); k.err != nil {
+			k.ticks = 0
+			return nil
+		}
+		settings.changed(g)
+		g.pop()
+		return nil
+	}
+	return nil
+}
+
+// Draw displays the action being bound, its current keys and any conflict with the key just pressed
+func (k *KeyCapture) Draw(screen *ebiten.Image, g *Game) {
+	g.txtRenderer.SetTarget(screen)
+	g.txtRenderer.SetAlign(etxt.YCenter, etxt.XCenter)
+	g.txtRenderer.SetColor(menuColorInactive)
+	g.txtRenderer.SetSizePx(32)
+	g.txtRenderer.Draw("Press a key for "+k.action.label(), winWidth/2, 160)
+
+	g.txtRenderer.SetSizePx(18)
+	g.txtRenderer.Draw("Now: "+bindingLabel(k.action), winWidth/2, 220)
+	if k.err != nil {
+		g.txtRenderer.SetColor(menuColorActive)
+		g.txtRenderer.Draw(k.err.Error(), winWidth/2, 280)
+		g.txtRenderer.SetColor(menuColorInactive)
+	}
+	g.txtRenderer.SetSizePx(15)
+	g.txtRenderer.Draw(fmt.Sprintf("Cancels in %d s   Right-click / B: Cancel", (keyCaptureTicks-k.ticks+59)/60), winWidth/2, 340)
+	g.txtRenderer.SetSizePx(32)
+}
+
+// showControls opens the Controls menu
+func showControls(g *Game) error {
+	pushMenu(g, newControlsMenu())
+	return nil
+}
+
+// newControlsMenu creates the Controls menu, listing the keys for each action with its gamepad buttons beneath; selecting one waits for a new key
+func newControlsMenu() *Menu {
+	var items []*MenuItem
+	for _, a := range actions {
+		a := a
+		items = append(items, &MenuItem{
+			option:      a.label(),
+			description: "Gamepad: " + gamepadLabel(a),
+			value:       func() string { return bindingLabel(a) },
+			action: func(g *Game) error {
+				g.push(NewKeyCapture(a))
+				return nil
+			},
+		})
+	}
+	items = append(items,
+		&MenuItem{option: "Reset to Defaults", action: func(g *Game) error {
+			settings.Bindings = defaultBindings()
+			settings.changed(g)
+			g.setNotice("Controls reset to defaults")
+			return nil
+		}},
+		NewMenuItem("Back", popMenu))
+	m := NewMenu(items)
+	m.header = "Controls"
+	return m
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	minDeadzone  = 5
+	maxDeadzone  = 60
+	deadzoneStep = 5
+)
+
+var (
+	// gamepads are the connected gamepads with the standard layout; others can't be mapped, so they are ignored
+	gamepads []ebiten.GamepadID
+
+	// stickHeld counts the ticks the left stick has been pushed toward each direction action, like a key press duration
+	stickHeld = map[Action]int{}
+
+	// gamepadBindings are the standard layout buttons for each action: d-pad to move, bottom face button to jump and confirm, right face button to go back, Start to pause
+	gamepadBindings = map[Action][]ebiten.StandardGamepadButton{
+		actionMoveLeft:  {ebiten.StandardGamepadButtonLeftLeft},
+		actionMoveRight: {ebiten.StandardGamepadButtonLeftRight},
+		actionMoveUp:    {ebiten.StandardGamepadButtonLeftTop},
+		actionMoveDown:  {ebiten.StandardGamepadButtonLeftBottom},
+		actionJump:      {ebiten.StandardGamepadButtonRightBottom},
+		actionConfirm:   {ebiten.StandardGamepadButtonRightBottom},
+		actionBack:      {ebiten.StandardGamepadButtonRightRight},
+		actionPause:     {ebiten.StandardGamepadButtonCenterRight},
+		actionPageUp:    {ebiten.StandardGamepadButtonFrontTopLeft},
+		actionPageDown:  {ebiten.StandardGamepadButtonFrontTopRight},
+	}
+
+	// gamepadButtonNames are how the buttons are labelled on a typical controller
+	gamepadButtonNames = map[ebiten.StandardGamepadButton]string{
+		ebiten.StandardGamepadButtonLeftLeft:      "D-Pad Left",
+		ebiten.StandardGamepadButtonLeftRight:     "D-Pad Right",
+		ebiten.StandardGamepadButtonLeftTop:       "D-Pad Up",
+		ebiten.StandardGamepadButtonLeftBottom:    "D-Pad Down",
+		ebiten.StandardGamepadButtonRightBottom:   "A",
+		ebiten.StandardGamepadButtonRightRight:    "B",
+		ebiten.StandardGamepadButtonCenterRight:   "Start",
+		ebiten.StandardGamepadButtonFrontTopLeft:  "LB",
+		ebiten.StandardGamepadButtonFrontTopRight: "RB",
+	}
+)
+
+// updateGamepads notices gamepads being plugged in and pulled out, and tracks how long the left stick has been held in each direction
+func updateGamepads(g *Game) {
+	for _, id := range inpututil.AppendJustConnectedGamepadIDs(nil) {
+		name := ebiten.GamepadName(id)
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			log.Printf("Gamepad %d (%s) has no standard layout, ignoring it", id, name)
+			continue
+		}
+		log.Printf("Gamepad %d (%s) connected", id, name)
+		gamepads = append(gamepads, id)
+		g.setNotice("Gamepad connected")
+	}
+	connected := gamepads[:0]
+	for _, id := range gamepads {
+		if inpututil.IsGamepadJustDisconnected(id) {
+			log.Printf("Gamepad %d disconnected", id)
+			g.setNotice("Gamepad disconnected")
+			continue
+		}
+		connected = append(connected, id)
+	}
+	gamepads = connected
+
+	held := map[Action]bool{}
+	for _, id := range gamepads {
+		x := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+		y := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+		for _, a := range stickActions(x, y, float64(settings.Deadzone)/100) {
+			held[a] = true
+		}
+	}
+	for _, a := range []Action{actionMoveLeft, actionMoveRight, actionMoveUp, actionMoveDown} {
+		if held[a] {
+			stickHeld[a]++
+		} else {
+			stickHeld[a] = 0
+		}
+	}
+}
+
+// stickActions gives the directions a stick at x, y is pushed past the deadzone, where -1, -1 is up and to the left
+func stickActions(x, y, deadzone float64) []Action {
+	var pushed []Action
+	switch {
+	case x < -deadzone:
+		pushed = append(pushed, actionMoveLeft)
+	case x > deadzone:
+		pushed = append(pushed, actionMoveRight)
+	}
+	switch {
+	case y < -deadzone:
+		pushed = append(pushed, actionMoveUp)
+	case y > deadzone:
+		pushed = append(pushed, actionMoveDown)
+	}
+	return pushed
+}
+
+// gamepadPressed reports whether a gamepad button or the left stick is held for a
+func gamepadPressed(a Action) bool {
+	return gamepadDuration(a) > 0
+}
+
+// gamepadJustPressed reports whether a gamepad button or the left stick was pushed for a this tick
+func gamepadJustPressed(a Action) bool {
+	return gamepadDuration(a) == 1
+}
+
+// gamepadDuration gives how many ticks a has been held on any gamepad, by button or stick
+func gamepadDuration(a Action) int {
+	d := stickHeld[a]
+	for _, id := range gamepads {
+		for _, b := range gamepadBindings[a] {
+			if bd := inpututil.StandardGamepadButtonPressDuration(id, b); bd > d {
+				d = bd
+			}
+		}
+	}
+	return d
+}
+
+// gamepadLabel names the gamepad buttons for an action, like "A"
+func gamepadLabel(a Action) string {
+	label := ""
+	for i, b := range gamepadBindings[a] {
+		if i > 0 {
+			label += " / "
+		}
+		label += gamepadButtonNames[b]
+	}
+	if stickDirection(a) {
+		label += " / Left Stick"
+	}
+	return label
+}
+
+// stickDirection reports whether the left stick can do a
+func stickDirection(a Action) bool {
+	return a == actionMoveLeft || a == actionMoveRight || a == actionMoveUp || a == actionMoveDown
+}
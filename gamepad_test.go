@@ -0,0 +1,38 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStickActions(t *testing.T) {
+	tests := []struct {
+		x, y float64
+		want []Action
+	}{
+		{0, 0, nil},
+		{0.2, -0.2, nil},
+		{-0.3, 0, []Action{actionMoveLeft}},
+		{1, 0.1, []Action{actionMoveRight}},
+		{0, -0.9, []Action{actionMoveUp}},
+		{-0.7, 0.7, []Action{actionMoveLeft, actionMoveDown}},
+	}
+	for _, tt := range tests {
+		if got := stickActions(tt.x, tt.y, 0.25); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("stickActions(%v, %v) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestEveryActionHasGamepadButton(t *testing.T) {
+	for _, a := range actions {
+		if len(gamepadBindings[a]) == 0 {
+			t.Errorf("%s has no gamepad button", a)
+		}
+		for _, b := range gamepadBindings[a] {
+			if gamepadButtonNames[b] == "" {
+				t.Errorf("%s button %d has no name", a, b)
+			}
+		}
+	}
+}
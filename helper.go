@@ -2,12 +2,14 @@ package main
 
 import (
 	"embed"
+	"errors"
+	"fmt"
 	"image/color"
 	"image/png"
 	"log"
-	"os"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/tinne26/etxt"
 )
 
@@ -19,6 +21,8 @@ var (
 	menuColorDisabled = color.RGBA{60, 60, 60, 255}
 	scoreDisplayColor = color.RGBA{0, 0, 0, 255}
 	messageBoxColor   = color.RGBA{0, 0, 0, 255}
+	mapPathColor      = color.RGBA{250, 220, 120, 255}
+	overlayColor      = color.RGBA{0, 0, 0, 220}
 
 	textColor color.RGBA
 )
@@ -36,8 +40,6 @@ var (
 	messageBox *ebiten.Image
 	statsBox   *ebiten.Image
 
-	world *ebiten.Image
-
 	gameOverMessage *ebiten.Image
 )
 
@@ -70,7 +72,6 @@ func newRenderer() *etxt.Renderer {
 
 func loadAssets() {
 	log.Printf("Loading Images...")
-	world = loadImage(FileSystem, "imgs/world--test.png")
 	gooAlley = loadImage(FileSystem, "imgs/goo-alley--test.png")
 	gooAlleyComplete = loadImage(FileSystem, "imgs/goo-alley--complete--test.png")
 	yikesfulMountain = loadImage(FileSystem, "imgs/yikesful-mountain--test.png")
@@ -99,24 +100,43 @@ func loadAssets() {
 	levelImages = map[string][]*ebiten.Image{
 		"Goo Alley":         {gooAlley, gooAlleyComplete, levelBG},
 		"Yikesful Mountain": {yikesfulMountain, yikesfulMountainComplete, backgroundYikesfulMountain},
+		"Blob Burrow":       {gooAlley, gooAlleyComplete, levelBG},
 	}
 }
 
+// findSaveFiles lists the used save slots as Load Game options
 func findSaveFiles() []string {
 	saveFiles := []string{}
-	files, err := os.ReadDir("./save/")
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, item := range files {
-		if string(item.Name()) != "README.md" {
-			saveFiles = append(saveFiles, string(item.Name()))
+	slotPreviews = map[int]*SaveData{}
+	for slot := 1; slot <= saveSlots; slot++ {
+		if !slotUsed(slot) {
+			continue
 		}
+		label := fmt.Sprintf("Slot %d: ", slot)
+		gameData, err := LoadGame(slot)
+		switch {
+		case errors.Is(err, errNewerSave):
+			label += "(newer version)"
+		case err != nil:
+			label += "(damaged)"
+		default:
+			label += gameData.Name
+			if gameData.Modified {
+				label += " (modified)"
+			}
+			slotPreviews[slot] = gameData
+		}
+		saveFiles = append(saveFiles, label)
 	}
-	saveFiles = append(saveFiles, "Main Menu")
 	return saveFiles
 }
 
+// playTime formats a count of game ticks (60 per second) as hours:minutes:seconds
+func playTime(count int) string {
+	seconds := count / 60
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
 func loadImage(fs embed.FS, path string) *ebiten.Image {
 	log.Printf(" %s", path)
 	rawFile, err := fs.Open(path)
@@ -132,3 +152,16 @@ func loadImage(fs embed.FS, path string) *ebiten.Image {
 	loadedImg := ebiten.NewImageFromImage(img)
 	return loadedImg
 }
+
+// drawArrow draws a small triangle centered on (x, y), pointing up or down, to show a menu scrolls
+func drawArrow(screen *ebiten.Image, x, y int, up bool, clr color.Color) {
+	const size = 8
+	for row := 0; row < size; row++ {
+		w := row*2 + 1
+		ry := y - size/2 + row
+		if !up {
+			ry = y + size/2 - row
+		}
+		ebitenutil.DrawRect(screen, float64(x-w/2), float64(ry), float64(w), 1, clr)
+	}
+}
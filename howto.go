@@ -0,0 +1,203 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"image"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/tinne26/etxt"
+)
+
+const demoPeriod = 240 // ticks before a demo starts over
+
+// demo area on screen, with a floor of bricks along the bottom
+var demoArea = image.Rect(100, 230, 500, 430)
+
+// HowToPage is one page of instructions; text may name key bindings with placeholders like {Jump}
+type HowToPage struct {
+	Title string
+	Text  []string
+	Demo  string // "walk", "jump", "gem", "portal" or none
+}
+
+// HowTo is a Game State that pages through instructions, each with a short animated demo
+type HowTo struct {
+	stateHooks
+	pages []*HowToPage
+	page  int
+	start int // g.count when the page was opened, so each demo starts from the beginning
+}
+
+func loadHowTo(fs embed.FS) []*HowToPage {
+	var pages []*HowToPage
+	content, err := fs.ReadFile("howto.json")
+	if err != nil {
+		log.Fatal("Error when opening file: ", err)
+	}
+
+	err = json.Unmarshal(content, &pages)
+	if err != nil {
+		log.Fatal("Error during Unmarshalling: ", err)
+	}
+	return pages
+}
+
+// showHowTo opens How To Play at the first page
+func showHowTo(g *Game) error {
+	g.push(&HowTo{pages: loadHowTo(FileSystem)})
+	return nil
+}
+
+// Enter starts the first page's demo from the beginning
+func (h *HowTo) Enter(g *Game) {
+	h.start = g.count
+}
+
+// turn moves d pages, leaving How To Play when paging past either end
+func (h *HowTo) turn(g *Game, d int) {
+	h.page += d
+	if h.page < 0 || h.page >= len(h.pages) {
+		g.pop()
+		return
+	}
+	h.start = g.count
+}
+
+// Update turns pages with the arrow keys, Enter or a click, and returns to the state beneath on Escape
+func (h *HowTo) Update(g *Game) error {
+	_, clicked := pointerJustPressed()
+	switch {
+	case actionJustPressed(actionBack) || backPressed():
+		g.pop()
+	case actionJustPressed(actionMoveRight) || actionJustPressed(actionConfirm) || clicked:
+		h.turn(g, 1)
+	case actionJustPressed(actionMoveLeft) && h.page > 0:
+		h.turn(g, -1)
+	}
+	return nil
+}
+
+// Draw displays the page title, instructions with current key bindings, the demo and the page number
+func (h *HowTo) Draw(screen *ebiten.Image, g *Game) {
+	p := h.pages[h.page]
+	g.txtRenderer.SetTarget(screen)
+	g.txtRenderer.SetAlign(etxt.YCenter, etxt.XCenter)
+	g.txtRenderer.SetColor(menuColorInactive)
+	g.txtRenderer.SetSizePx(32)
+	g.txtRenderer.Draw(p.Title, winWidth/2, 60)
+
+	g.txtRenderer.SetSizePx(18)
+	locY := 110
+	for _, line := range p.Text {
+		g.txtRenderer.Draw(resolveBindings(line), winWidth/2, locY)
+		locY += 28
+	}
+
+	if p.Demo != "" {
+		h.drawDemo(screen, g, p.Demo, g.count-h.start)
+		g.txtRenderer.SetTarget(screen)
+	}
+
+	g.txtRenderer.SetSizePx(15)
+	g.txtRenderer.Draw(fmt.Sprintf("<  Page %d of %d  >", h.page+1, len(h.pages)), winWidth/2, winHeight-15)
+	g.txtRenderer.SetSizePx(32)
+}
+
+// drawDemo animates a mechanic in the demo area, t ticks after the page opened
+func (h *HowTo) drawDemo(screen *ebiten.Image, g *Game, demo string, t int) {
+	area := screen.SubImage(demoArea).(*ebiten.Image)
+	ebitenutil.DrawRect(area, float64(demoArea.Min.X), float64(demoArea.Min.Y), float64(demoArea.Dx()), float64(demoArea.Dy()), menuColorDisabled)
+	floorY := demoArea.Max.Y - blockHW
+	for x := demoArea.Min.X; x < demoArea.Max.X; x += blockHW {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(x), float64(floorY))
+		area.DrawImage(brick, op)
+	}
+
+	t %= demoPeriod
+	left, right := demoArea.Min.X+10, demoArea.Max.X-playerCharWidth-10
+	charX, charY := left, floorY-playerCharHeight
+	facing := 0
+	walking := true
+	visible := true
+
+	switch demo {
+	case "walk":
+		half := demoPeriod / 2
+		if t < half {
+			charX = left + (right-left)*t/half
+		} else {
+			charX = right - (right-left)*(t-half)/half
+			facing = playerCharHeight
+		}
+	case "jump":
+		const jumpTicks, peak = 40, 70
+		charX = (demoArea.Min.X + demoArea.Max.X - playerCharWidth) / 2
+		walking = false
+		jt := t % 90
+		pressed := jt < jumpTicks
+		if pressed {
+			charY -= 4 * peak * jt * (jumpTicks - jt) / (jumpTicks * jumpTicks)
+		}
+		drawKeyCap(area, g, bindingLabel("Jump"), pressed)
+	case "gem":
+		gemX := demoArea.Max.X - 120
+		charX = left + (gemX-left)*clamp(t, 0, 150)/150
+		walking = t < 150
+		collected := charX+playerCharWidth/2 >= gemX
+		if !collected {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(float64(gemX), float64(floorY-blockHW))
+			gx := (g.count / 5) % treasureTypeList[3].frameCt * blockHW
+			area.DrawImage(portalGem.SubImage(image.Rect(gx, 0, gx+blockHW, blockHW)).(*ebiten.Image), op)
+		}
+		gx := 0
+		if collected {
+			gx = 35
+		}
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(demoArea.Min.X+10), float64(demoArea.Min.Y+10))
+		area.DrawImage(gemCt.SubImage(image.Rect(gx, 0, gx+35, 35)).(*ebiten.Image), op)
+	case "portal":
+		portalX := demoArea.Max.X - portalWidth - 20
+		enter := portalX + (portalWidth-playerCharWidth)/2
+		charX = left + (enter-left)*clamp(t, 0, 150)/150
+		walking = t < 150
+		visible = t < 160
+
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(portalX), float64(floorY-portalHeight))
+		px := (g.count / 5) % portalFrameCount * portalWidth
+		area.DrawImage(portal.SubImage(image.Rect(px, 0, px+portalWidth, portalHeight)).(*ebiten.Image), op)
+	}
+
+	if !visible {
+		return
+	}
+	cx := defaultFrame * playerCharWidth
+	if walking {
+		cx = (g.count / 5) % frameCount * playerCharWidth
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(charX), float64(charY))
+	area.DrawImage(spriteSheet.SubImage(image.Rect(cx, facing, cx+playerCharWidth, facing+playerCharHeight)).(*ebiten.Image), op)
+}
+
+// drawKeyCap labels a key in the corner of the demo area, lit while the demo is pressing it
+func drawKeyCap(area *ebiten.Image, g *Game, label string, pressed bool) {
+	clr := menuColorInactive
+	if pressed {
+		clr = menuColorActive
+	}
+	g.txtRenderer.SetTarget(area)
+	g.txtRenderer.SetAlign(etxt.Top, etxt.Left)
+	g.txtRenderer.SetSizePx(18)
+	g.txtRenderer.SetColor(clr)
+	g.txtRenderer.Draw(label, demoArea.Min.X+10, demoArea.Min.Y+10)
+	g.txtRenderer.SetAlign(etxt.YCenter, etxt.XCenter)
+	g.txtRenderer.SetColor(menuColorInactive)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveBindings(t *testing.T) {
+	got := resolveBindings("Press {Jump} to jump, {Back} to leave, {Dance} to dance")
+	want := "Press Space to jump, Escape / Backspace to leave, {Dance} to dance"
+	if got != want {
+		t.Errorf("resolveBindings = %q, want %q", got, want)
+	}
+}
+
+func TestHowToPagesUseKnownBindings(t *testing.T) {
+	pages := loadHowTo(FileSystem)
+	if len(pages) == 0 {
+		t.Fatal("no How To Play pages")
+	}
+	demos := map[string]bool{"": true, "walk": true, "jump": true, "gem": true, "portal": true}
+	for _, p := range pages {
+		if !demos[p.Demo] {
+			t.Errorf("page %q has unknown demo %q", p.Title, p.Demo)
+		}
+		for _, line := range p.Text {
+			if resolved := resolveBindings(line); strings.ContainsAny(resolved, "{}") {
+				t.Errorf("page %q has an unknown binding: %q", p.Title, line)
+			}
+		}
+	}
+}
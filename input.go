@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Action is something the player does, whichever keys are bound to it
+type Action string
+
+const (
+	actionMoveLeft  Action = "MoveLeft"
+	actionMoveRight Action = "MoveRight"
+	actionMoveUp    Action = "MoveUp"
+	actionMoveDown  Action = "MoveDown"
+	actionJump      Action = "Jump"
+	actionConfirm   Action = "Confirm"
+	actionBack      Action = "Back"
+	actionPause     Action = "Pause"
+	actionPageUp    Action = "PageUp"
+	actionPageDown  Action = "PageDown"
+)
+
+var (
+	lastCursor image.Point
+
+	// actions in the order the Controls menu lists them
+	actions = []Action{actionMoveLeft, actionMoveRight, actionMoveUp, actionMoveDown, actionJump,
+		actionConfirm, actionBack, actionPause, actionPageUp, actionPageDown}
+
+	// actionContexts are the actions read together in a level, on the World and in menus; within one, no two actions may share a key
+	actionContexts = [][]Action{
+		{actionMoveLeft, actionMoveRight, actionJump, actionPause},
+		{actionMoveLeft, actionMoveRight, actionMoveUp, actionMoveDown, actionConfirm, actionPause},
+		{actionMoveLeft, actionMoveRight, actionMoveUp, actionMoveDown, actionConfirm, actionBack, actionPageUp, actionPageDown},
+	}
+
+	bindingPattern = regexp.MustCompile(`\{(\w+)\}`)
+)
+
+// defaultBindings lists the keys for each action before the player changes any
+func defaultBindings() map[Action][]ebiten.Key {
+	return map[Action][]ebiten.Key{
+		actionMoveLeft:  {ebiten.KeyArrowLeft},
+		actionMoveRight: {ebiten.KeyArrowRight},
+		actionMoveUp:    {ebiten.KeyArrowUp},
+		actionMoveDown:  {ebiten.KeyArrowDown},
+		actionJump:      {ebiten.KeySpace},
+		actionConfirm:   {ebiten.KeyEnter},
+		actionBack:      {ebiten.KeyEscape, ebiten.KeyBackspace},
+		actionPause:     {ebiten.KeyEscape},
+		actionPageUp:    {ebiten.KeyPageUp},
+		actionPageDown:  {ebiten.KeyPageDown},
+	}
+}
+
+// label gives the action's name with spaces, like "Move Left"
+func (a Action) label() string {
+	var b strings.Builder
+	for i, r := range a {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// actionPressed reports whether any key or gamepad button bound to a is held
+func actionPressed(a Action) bool {
+	for _, k := range settings.Bindings[a] {
+		if ebiten.IsKeyPressed(k) {
+			return true
+		}
+	}
+	return gamepadPressed(a)
+}
+
+// actionJustPressed reports whether a key or gamepad button bound to a was pressed this tick
+func actionJustPressed(a Action) bool {
+	for _, k := range settings.Bindings[a] {
+		if inpututil.IsKeyJustPressed(k) {
+			return true
+		}
+	}
+	return gamepadJustPressed(a)
+}
+
+// actionDuration gives how many ticks a has been held, by whichever of its keys or gamepad buttons was pressed first, or 0 when it is up
+func actionDuration(a Action) int {
+	d := gamepadDuration(a)
+	for _, k := range settings.Bindings[a] {
+		if kd := inpututil.KeyPressDuration(k); kd > d {
+			d = kd
+		}
+	}
+	return d
+}
+
+// actionRepeating reports a press of a, then repeats it while held, for stepping through menus
+func actionRepeating(a Action) bool {
+	return repeating(actionDuration(a))
+}
+
+// bindingConflict finds another action that shares a context with a and already uses k
+func bindingConflict(bindings map[Action][]ebiten.Key, a Action, k ebiten.Key) (Action, bool) {
+	for _, context := range actionContexts {
+		if !containsAction(context, a) {
+			continue
+		}
+		for _, other := range context {
+			if other == a {
+				continue
+			}
+			for _, ok := range bindings[other] {
+				if ok == k {
+					return other, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+func containsAction(list []Action, a Action) bool {
+	for _, l := range list {
+		if l == a {
+			return true
+		}
+	}
+	return false
+}
+
+// rebind makes k the main key for a in bindings, in place of its first key, unless k is taken by an action used alongside it
+func rebind(bindings map[Action][]ebiten.Key, a Action, k ebiten.Key) error {
+	if other, taken := bindingConflict(bindings, a, k); taken {
+		return fmt.Errorf("%s is already used for %s", keyName(k), other.label())
+	}
+	keys := []ebiten.Key{k}
+	for i, old := range bindings[a] {
+		if i > 0 && old != k {
+			keys = append(keys, old)
+		}
+	}
+	bindings[a] = keys
+	log.Printf("Bound %s to %s", a, keyName(k))
+	return nil
+}
+
+// keyName gives the name of a key as a player would say it
+func keyName(k ebiten.Key) string {
+	name := k.String()
+	if strings.HasPrefix(name, "Arrow") {
+		return strings.TrimPrefix(name, "Arrow") + " Arrow"
+	}
+	return name
+}
+
+// bindingLabel names the keys bound to an action, like "Escape / Backspace"
+func bindingLabel(a Action) string {
+	var names []string
+	for _, k := range settings.Bindings[a] {
+		names = append(names, keyName(k))
+	}
+	return strings.Join(names, " / ")
+}
+
+// resolveBindings replaces placeholders like {Jump} with the keys currently bound to that action, leaving unknown placeholders as they are
+func resolveBindings(text string) string {
+	return bindingPattern.ReplaceAllStringFunc(text, func(p string) string {
+		a := Action(p[1 : len(p)-1])
+		if !containsAction(actions, a) {
+			return p
+		}
+		return bindingLabel(a)
+	})
+}
+
+// cursorMoved gives the mouse position, and whether it moved since the last check, so a resting cursor doesn't fight the arrow keys
+func cursorMoved() (image.Point, bool) {
+	x, y := ebiten.CursorPosition()
+	pt := image.Pt(x, y)
+	moved := pt != lastCursor
+	lastCursor = pt
+	return pt, moved
+}
+
+// pointerJustPressed gives where the left mouse button was clicked or the screen was tapped this tick
+func pointerJustPressed() (image.Point, bool) {
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		x, y := ebiten.CursorPosition()
+		return image.Pt(x, y), true
+	}
+	for _, id := range inpututil.AppendJustPressedTouchIDs(nil) {
+		x, y := ebiten.TouchPosition(id)
+		return image.Pt(x, y), true
+	}
+	return image.Point{}, false
+}
+
+// backPressed reports whether the right mouse button was clicked, which leaves a menu like Escape
+func backPressed() bool {
+	return inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight)
+}
+
+// wheelSteps gives how many items the mouse wheel moved this tick, positive when scrolling down
+func wheelSteps() int {
+	_, dy := ebiten.Wheel()
+	switch {
+	case dy > 0:
+		return -1
+	case dy < 0:
+		return 1
+	}
+	return 0
+}
+
+// repeatingKeyPressed reports a key press, then repeats it while the key is held, like typing in a text field
+func repeatingKeyPressed(key ebiten.Key) bool {
+	return repeating(inpututil.KeyPressDuration(key))
+}
+
+// repeating reports whether a press held for d ticks fires this tick: once at first, then steadily after a delay
+func repeating(d int) bool {
+	const (
+		delay    = 30
+		interval = 3
+	)
+	return d == 1 || (d >= delay && (d-delay)%interval == 0)
+}
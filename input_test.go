@@ -0,0 +1,54 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestDefaultBindingsHaveNoConflicts(t *testing.T) {
+	bindings := defaultBindings()
+	for _, a := range actions {
+		if len(bindings[a]) == 0 {
+			t.Errorf("%s has no default key", a)
+		}
+		for _, k := range bindings[a] {
+			if other, taken := bindingConflict(bindings, a, k); taken {
+				t.Errorf("%s and %s both use %s by default", a, other, keyName(k))
+			}
+		}
+	}
+}
+
+func TestRebind(t *testing.T) {
+	bindings := defaultBindings()
+	if err := rebind(bindings, actionJump, ebiten.KeyArrowLeft); err == nil {
+		t.Error("binding Jump to Left Arrow, used for Move Left in a level, err = nil")
+	}
+	if err := rebind(bindings, actionJump, ebiten.KeyEscape); err == nil {
+		t.Error("binding Jump to Escape, used for Pause, err = nil")
+	}
+	if err := rebind(bindings, actionJump, ebiten.KeyEnter); err != nil {
+		t.Errorf("binding Jump to Enter, only used for Confirm outside levels: %v", err)
+	}
+
+	if err := rebind(bindings, actionBack, ebiten.KeyQ); err != nil {
+		t.Fatalf("binding Back to Q: %v", err)
+	}
+	if want := []ebiten.Key{ebiten.KeyQ, ebiten.KeyBackspace}; !reflect.DeepEqual(bindings[actionBack], want) {
+		t.Errorf("Back keys = %v, want Q in place of Escape, keeping Backspace", bindings[actionBack])
+	}
+	if err := rebind(bindings, actionBack, ebiten.KeyBackspace); err != nil {
+		t.Fatalf("binding Back to Backspace: %v", err)
+	}
+	if want := []ebiten.Key{ebiten.KeyBackspace}; !reflect.DeepEqual(bindings[actionBack], want) {
+		t.Errorf("Back keys = %v, want Backspace once", bindings[actionBack])
+	}
+}
+
+func TestActionLabel(t *testing.T) {
+	if got := actionMoveLeft.label(); got != "Move Left" {
+		t.Errorf("label = %q, want Move Left", got)
+	}
+}
@@ -4,19 +4,20 @@ package main
 import (
 	"embed"
 	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/tinne26/etxt"
 )
 
 const (
 	winWidth  = 600
 	winHeight = 480
-
-	gravity = 20
-	radius  = 375.0
 )
 
 var (
@@ -27,6 +28,11 @@ var (
 	//go:embed imgs
 	//go:embed fonts
 	//go:embed levels.json
+	//go:embed worldmap.json
+	//go:embed worlds.json
+	//go:embed levels-vorp-minor.json
+	//go:embed worldmap-vorp-minor.json
+	//go:embed howto.json
 	FileSystem embed.FS
 )
 
@@ -39,13 +45,42 @@ func main() {
 		defer f.Close()
 		log.SetOutput(f)
 	*/
+	saveDirFlag := flag.String("save-dir", "", "directory to keep save files in (default: in the user config directory)")
+	replayFlag := flag.String("replay", "", "replay file to play back, like last.replay in the user config directory")
+	flag.Func("seed", "seed for every level's randomness, in place of the one kept in each save", func(v string) error {
+		seed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return err
+		}
+		seedOverride = &seed
+		return nil
+	})
+	flag.Parse()
+
 	log.Printf("Starting up game...")
+	saveDir := defaultSaveDir()
+	if *saveDirFlag != "" {
+		saveDir = *saveDirFlag
+	}
+	prepareSaveStore(saveDir)
+	settingsStore = NewFileStore(defaultConfigDir())
+	settings = loadSettings(settingsStore)
+	replayStore = settingsStore
+	if *replayFlag != "" {
+		replay, err := readReplayFile(*replayFlag)
+		if err != nil {
+			log.Fatalf("Error reading replay: %v", err)
+		}
+		pendingReplay = replay
+	}
 	loadAssets()
-	ebiten.SetWindowSize(winWidth, winHeight)
+	settings.apply()
 	ebiten.SetWindowTitle("A Pixely Side-Scrolling Game Send-up")
 
 	g := NewGame()
-	if err := ebiten.RunGame(g); err != nil {
+	err := ebiten.RunGame(g)
+	g.exitAll()
+	if err != nil {
 		if err == ErrExit {
 			os.Exit(0)
 		}
@@ -55,53 +90,79 @@ func main() {
 
 // Game contains all relevant data for game
 type Game struct {
-	state       map[string]State
-	mode        string
+	states      []State // stack of states; the top one is updated, and drawn over any beneath it
+	transition  *Transition
 	txtRenderer *etxt.Renderer
+	session     *Session // game in progress, nil until one is started or loaded
+	levelTotal  int      // levels across every planet, for save previews
 	count       int
 	timer       int
-	score       int
-}
-
-// State describes Game State
-type State interface {
-	Update(g *Game) error
-	Draw(screen *ebiten.Image, g *Game)
+	notice      string
+	noticeTimer int
 }
 
 // NewGame creates a new Game instance (used once, to run program)
 func NewGame() *Game {
 	log.Printf("Generating new game instance")
-	game := &Game{
-		state: map[string]State{
-			"Load":  &Load{splash: splashImages},
-			"Title": &Title{},
-			"World": &World{},
-			"Play":  &Play{},
-			"Pause": &Pause{},
-			"Info":  &Info{},
-		},
-		mode: "Load",
-	}
+	game := &Game{}
+	game.push(&Load{splash: splashImages})
 	return game
 }
 
 // Update controls all game logic updates. It is part of the main game loop in Ebitengine.
 func (g *Game) Update() error {
 	g.count++
-	err := g.state[g.mode].Update(g)
-	return err
+	updateGamepads(g)
+	if g.session != nil {
+		g.session.playTime++
+	}
+	if g.noticeTimer > 0 {
+		g.noticeTimer--
+	}
+	if g.transition != nil {
+		if g.transition.update() {
+			g.transition = nil
+		}
+		return nil
+	}
+	top := g.top()
+	if top == nil {
+		log.Printf("No state left to update, returning to Title")
+		g.push(NewTitle())
+		return nil
+	}
+	return top.Update(g)
 }
 
 // Draw contains all code for drawing images to screen. It is part of the main game loop in Ebitengine.
 func (g *Game) Draw(screen *ebiten.Image) {
-	switch g.mode {
-	case "Pause":
-		g.state["Play"].Draw(screen, g)
-		g.state[g.mode].Draw(screen, g)
-	default:
-		g.state[g.mode].Draw(screen, g)
+	if g.transition != nil {
+		g.drawTransition(screen)
+	} else {
+		g.drawStates(screen)
 	}
+	if g.noticeTimer > 0 {
+		g.txtRenderer.SetAlign(etxt.YCenter, etxt.XCenter)
+		g.txtRenderer.SetTarget(screen)
+		g.txtRenderer.SetSizePx(18)
+		g.txtRenderer.SetColor(menuColorInactive)
+		g.txtRenderer.Draw(g.notice, winWidth/2, winHeight-30)
+		g.txtRenderer.SetSizePx(32)
+	}
+	if settings.ShowFPS {
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("FPS %.0f", ebiten.ActualFPS()), winWidth-60, 4)
+		if play, ok := findState[*Play](g); ok {
+			seed := fmt.Sprintf("Seed %d", play.level.Seed)
+			ebitenutil.DebugPrintAt(screen, seed, winWidth-6*len(seed)-4, 20)
+		}
+	}
+}
+
+// setNotice shows a short message along the bottom of the screen for a few seconds
+func (g *Game) setNotice(msg string) {
+	log.Print(msg)
+	g.notice = msg
+	g.noticeTimer = 180
 }
 
 // Layout controls the game window and scaling. It is part of the main game loop in Ebitengine.
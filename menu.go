@@ -1,61 +1,289 @@
 package main
 
-import "log"
+import (
+	"fmt"
+	"image"
+	"log"
+)
 
 // Menu is a cyclical doubly-linked list with selectable MenuItems and an active option
 type Menu struct {
-	head   *MenuItem
-	tail   *MenuItem
-	active *MenuItem
-	length int
+	head    *MenuItem
+	tail    *MenuItem
+	active  *MenuItem
+	length  int
+	header  string
+	body    []string // optional lines of text shown between the header and the options
+	details bool     // draw every item's description beneath it, instead of only the active item's
+	offset  int      // index of the first item in the visible window
+	visible int      // how many items fit on screen; 0 shows them all
+	onMove  func()   // optional; called whenever the active item changes
+}
+
+// MenuStack holds nested menus; submenus are pushed on top and Back pops them, leaving the root menu
+type MenuStack struct {
+	menus []*Menu
+}
+
+// menuHolder is a Game State that shows a MenuStack
+type menuHolder interface {
+	menuStack() *MenuStack
 }
 
 // MenuItem describes a selectable option as a node in a Menu
 type MenuItem struct {
-	option string
-	prev   *MenuItem
-	next   *MenuItem
+	option      string
+	description string               // optional line explaining the option
+	action      func(g *Game) error  // run when the option is selected
+	enabled     func() bool          // optional; the option is skipped and greyed out while this is false
+	value       func() string        // optional current value of a toggle or slider, shown beside the option
+	adjust      func(g *Game, d int) // optional; changes value by d steps on left/right
+	rect        image.Rectangle      // where the option was last drawn, for mouse and touch
+	prev        *MenuItem
+	next        *MenuItem
 }
 
 var (
-	mainHeader     = gameTitle
-	worldHeader    = "Options"
-	loadHeader     = "Saved Games"
-	mainMenuItems  = []string{"New Game", "Load Game", "How To Play", "Acknowledgements", "Exit"}
-	worldMenuItems = []string{"Save", "Stats", "Main Menu", "Quit"}
-	loadMenuItems  []string
-	mainMenu       *Menu
-	worldMenu      *Menu
-	loadMenu       *Menu
+	mainHeader  = gameTitle
+	worldHeader = "Options"
+	loadHeader  = "Saved Games"
+
+	loadMenuItems []string
+	mainMenu      *Menu
+	loadMenu      *Menu
 )
 
-func initializeMenus() {
-	mainMenu = NewMenu(mainMenuItems)
-	worldMenu = NewMenu(worldMenuItems)
-	loadMenu = NewMenu(loadMenuItems)
+func initializeMenus(g *Game) {
+	mainMenu = NewMenu([]*MenuItem{
+		NewMenuItem("New Game", startNewGame),
+		{option: "Load Game", action: showLoadMenu, enabled: hasSaves},
+		NewMenuItem("How To Play", showHowTo),
+		NewMenuItem("Settings", showSettings),
+		NewMenuItem("High Scores", showHighScores),
+		NewMenuItem("Acknowledgements", showAcknowledgements),
+		NewMenuItem("Exit", exitGame),
+	})
+	mainMenu.header = mainHeader
+	refreshLoadMenu(g)
+}
+
+// refreshLoadMenu rebuilds the Load Game menu from the save slots in use
+func refreshLoadMenu(g *Game) {
+	loadMenuItems = findSaveFiles()
+	var items []*MenuItem
+	for _, label := range loadMenuItems {
+		slot := slotFromOption(label)
+		item := NewMenuItem(label, func(g *Game) error {
+			showSlotMenu(g, slot)
+			return nil
+		})
+		if preview, ok := slotPreviews[slot]; ok {
+			item.description = preview.preview(g.levelTotal)
+		}
+		items = append(items, item)
+	}
+	items = append(items, NewMenuItem("Back", popMenu))
+	loadMenu = NewMenu(items)
+	loadMenu.header = loadHeader
+	loadMenu.details = true
+}
+
+// newSlotMenu creates the actions available for a saved game
+func newSlotMenu(slot, totalLevels int) *Menu {
+	confirmDelete := false
+	m := NewMenu([]*MenuItem{
+		NewMenuItem("Load", func(g *Game) error {
+			loadSlot(g, slot)
+			return nil
+		}),
+		{option: "Rename", description: "Change the character's name", action: func(g *Game) error {
+			name := ""
+			if preview, ok := slotPreviews[slot]; ok {
+				name = preview.Name
+			}
+			g.push(NewTextInput(fmt.Sprintf("Rename slot %d", slot), name, func(g *Game, text string) error {
+				g.pop()
+				if err := RenameSave(slot, text); err != nil {
+					g.setNotice("Could not rename save: " + err.Error())
+					return nil
+				}
+				g.setNotice(fmt.Sprintf("Renamed slot %d to %s", slot, text))
+				return reloadLoadMenu(g)
+			}))
+			return nil
+		}, enabled: func() bool { return slotPreviews[slot] != nil }},
+		{option: "Copy", description: "Copy into the first free slot", action: func(g *Game) error {
+			to, err := CopySave(slot)
+			if err != nil {
+				g.setNotice("Could not copy save: " + err.Error())
+				return nil
+			}
+			g.setNotice(fmt.Sprintf("Copied slot %d to slot %d", slot, to))
+			return reloadLoadMenu(g)
+		}},
+		{option: "Delete", description: "Select twice to erase this save", action: func(g *Game) error {
+			if !confirmDelete {
+				confirmDelete = true
+				g.setNotice("Select Delete again to erase this save")
+				return nil
+			}
+			if err := DeleteSave(slot); err != nil {
+				g.setNotice("Could not delete save: " + err.Error())
+				return nil
+			}
+			g.setNotice(fmt.Sprintf("Deleted slot %d", slot))
+			return reloadLoadMenu(g)
+		}},
+		NewMenuItem("Back", popMenu),
+	})
+	m.onMove = func() { confirmDelete = false }
+	m.header = fmt.Sprintf("Slot %d", slot)
+	if preview, ok := slotPreviews[slot]; ok {
+		m.body = []string{preview.Name + "  " + preview.preview(totalLevels)}
+	}
+	return m
+}
+
+// newWorldMenu creates the World options menu
+func newWorldMenu(w *World) *Menu {
+	m := NewMenu([]*MenuItem{
+		{option: "Save", description: "Save progress to this game's slot", action: w.save},
+		{option: "Stats", description: "Score, lives and levels completed", action: w.showStats},
+		{option: "Settings", description: "Display, gamepad and controls", action: showSettings},
+		{option: "Main Menu", description: "Return to the title screen", action: w.mainMenu},
+		{option: "Quit", description: "Exit the game", action: w.quit},
+	})
+	m.header = worldHeader
+	return m
+}
+
+// newPage creates a menu that only shows text, with Back as its one option
+func newPage(header string, body []string) *Menu {
+	m := NewMenu([]*MenuItem{NewMenuItem("Back", popMenu)})
+	m.header = header
+	m.body = body
+	return m
+}
+
+func hasSaves() bool {
+	return len(loadMenuItems) > 0
+}
+
+// activeMenus gives the MenuStack of the current Game State, if it shows menus
+func activeMenus(g *Game) *MenuStack {
+	if h, ok := g.top().(menuHolder); ok {
+		return h.menuStack()
+	}
+	return nil
+}
+
+// pushMenu opens a submenu on top of the current menu
+func pushMenu(g *Game, m *Menu) {
+	if s := activeMenus(g); s != nil {
+		s.Push(m)
+	}
+}
+
+// popMenu returns to the menu beneath the current one
+func popMenu(g *Game) error {
+	if s := activeMenus(g); s != nil {
+		s.Pop()
+	}
+	return nil
+}
+
+// reloadLoadMenu leaves a slot's menu for the refreshed Load Game menu, or the main menu once no saves are left
+func reloadLoadMenu(g *Game) error {
+	s := activeMenus(g)
+	if s == nil {
+		return nil
+	}
+	s.Pop()
+	refreshLoadMenu(g)
+	if hasSaves() {
+		s.Replace(loadMenu)
+	} else {
+		s.Pop()
+	}
+	return nil
+}
+
+// NewMenuStack creates a MenuStack with root as the bottom menu
+func NewMenuStack(root *Menu) *MenuStack {
+	return &MenuStack{menus: []*Menu{root}}
+}
+
+// Push opens m on top of the stack
+func (s *MenuStack) Push(m *Menu) {
+	log.Printf("Opening menu %s", m.header)
+	s.menus = append(s.menus, m)
+}
+
+// Pop closes the top menu, never removing the root menu
+func (s *MenuStack) Pop() {
+	if len(s.menus) > 1 {
+		s.menus = s.menus[:len(s.menus)-1]
+	}
+}
+
+// Replace swaps the top menu for m
+func (s *MenuStack) Replace(m *Menu) {
+	s.menus[len(s.menus)-1] = m
+}
+
+// Reset closes every submenu, back to the root menu
+func (s *MenuStack) Reset() {
+	s.menus = s.menus[:1]
 }
 
-// NewMenu creates a Menu from a slice of strings
-func NewMenu(items []string) *Menu {
+// Top gives the menu currently shown
+func (s *MenuStack) Top() *Menu {
+	return s.menus[len(s.menus)-1]
+}
+
+// Len gives how many menus are open, including the root
+func (s *MenuStack) Len() int {
+	return len(s.menus)
+}
+
+// NewMenu creates a Menu from a slice of MenuItems, with the first enabled item active
+func NewMenu(items []*MenuItem) *Menu {
 	menu := &Menu{}
 	for _, v := range items {
 		menu.appendItem(v)
 		menu.length++
 	}
 	menu.active = menu.head
+	menu.settle()
 	return menu
 }
 
-// NewMenuItem creates a MenuItem node
-func NewMenuItem(o string) *MenuItem {
+// NewMenuItem creates a MenuItem node that runs action when selected
+func NewMenuItem(o string, action func(g *Game) error) *MenuItem {
 	menuItem := &MenuItem{
 		option: o,
+		action: action,
 	}
 	return menuItem
 }
 
-func (m *Menu) appendItem(s string) {
-	addition := NewMenuItem(s)
+// Enabled reports whether the item can currently be selected
+func (i *MenuItem) Enabled() bool {
+	return i.enabled == nil || i.enabled()
+}
+
+// Label gives the option text with any current value, in arrows when left and right change it
+func (i *MenuItem) Label() string {
+	switch {
+	case i.value == nil:
+		return i.option
+	case i.adjust == nil:
+		return fmt.Sprintf("%s: %s", i.option, i.value())
+	}
+	return fmt.Sprintf("%s: < %s >", i.option, i.value())
+}
+
+func (m *Menu) appendItem(addition *MenuItem) {
 	if m.head == nil {
 		m.head = addition
 		m.tail = addition
@@ -67,20 +295,174 @@ func (m *Menu) appendItem(s string) {
 	m.head.prev = m.tail
 }
 
-// Next changes active menu selection to next item
+// settle moves the active selection forward off a disabled item, if any item is enabled
+func (m *Menu) settle() {
+	for i := 0; i < m.length && !m.active.Enabled(); i++ {
+		m.active = m.active.next
+	}
+}
+
+// Next changes active menu selection to next enabled item
 func (m *Menu) Next() {
 	log.Printf("Next Option")
-	m.active = m.active.next
+	item := m.active
+	for i := 0; i < m.length; i++ {
+		item = item.next
+		if item.Enabled() {
+			break
+		}
+	}
+	m.setActive(item)
 }
 
-// Prev changes active menu selection to previous item
+// Prev changes active menu selection to previous enabled item
 func (m *Menu) Prev() {
 	log.Printf("Previous Option")
-	m.active = m.active.prev
+	item := m.active
+	for i := 0; i < m.length; i++ {
+		item = item.prev
+		if item.Enabled() {
+			break
+		}
+	}
+	m.setActive(item)
+}
+
+// setActive makes item the active selection, scrolling it into view and telling onMove if it changed
+func (m *Menu) setActive(item *MenuItem) {
+	moved := item != m.active
+	m.active = item
+	m.scrollToActive()
+	if moved && m.onMove != nil {
+		m.onMove()
+	}
+}
+
+// PageDown moves the active selection a window further down, stopping at the last enabled item rather than wrapping
+func (m *Menu) PageDown() {
+	m.page(func(i *MenuItem) *MenuItem { return i.next }, m.tail)
+}
+
+// PageUp moves the active selection a window further up, stopping at the first enabled item rather than wrapping
+func (m *Menu) PageUp() {
+	m.page(func(i *MenuItem) *MenuItem { return i.prev }, m.head)
+}
+
+func (m *Menu) page(step func(*MenuItem) *MenuItem, end *MenuItem) {
+	jump := m.visible
+	if jump <= 0 {
+		jump = m.length
+	}
+	target := m.active
+	for item := m.active; item != end && jump > 0; {
+		item = step(item)
+		if item.Enabled() {
+			target = item
+			jump--
+		}
+	}
+	m.setActive(target)
+}
+
+// setVisible sets how many items fit on screen, keeping the active item in view
+func (m *Menu) setVisible(rows int) {
+	m.visible = rows
+	m.scrollToActive()
+}
+
+// scrollToActive moves the visible window just far enough to show the active item
+func (m *Menu) scrollToActive() {
+	if m.visible <= 0 || m.length <= m.visible {
+		m.offset = 0
+		return
+	}
+	i := m.indexOf(m.active)
+	if i < m.offset {
+		m.offset = i
+	}
+	if i >= m.offset+m.visible {
+		m.offset = i - m.visible + 1
+	}
+	m.offset = clamp(m.offset, 0, m.length-m.visible)
+}
+
+// indexOf gives the position of item in the menu, counting from the head
+func (m *Menu) indexOf(item *MenuItem) int {
+	curr := m.head
+	for i := 0; i < m.length; i++ {
+		if curr == item {
+			return i
+		}
+		curr = curr.next
+	}
+	return -1
+}
+
+// window gives the first visible item and how many items are visible, along with whether items are hidden above or below
+func (m *Menu) window() (first *MenuItem, count int, above, below bool) {
+	first = m.head
+	for i := 0; i < m.offset; i++ {
+		first = first.next
+	}
+	count = m.length
+	if m.visible > 0 && m.visible < m.length {
+		count = m.visible
+	}
+	return first, count, m.offset > 0, m.offset+count < m.length
+}
+
+// Select runs the action of the active MenuItem, if it is enabled
+func (m *Menu) Select(g *Game) error {
+	if !m.active.Enabled() || m.active.action == nil {
+		return nil
+	}
+	log.Printf("Selecting %s", m.active.option)
+	return m.active.action(g)
+}
+
+// Adjust changes the value of the active MenuItem by d steps, for toggles and sliders
+func (m *Menu) Adjust(g *Game, d int) {
+	if m.active.Enabled() && m.active.adjust != nil {
+		m.active.adjust(g, d)
+	}
+}
+
+// itemAt finds the MenuItem drawn at pt
+func (m *Menu) itemAt(pt image.Point) *MenuItem {
+	item := m.head
+	for i := m.length; i > 0; i-- {
+		if pt.In(item.rect) {
+			return item
+		}
+		item = item.next
+	}
+	return nil
+}
+
+// UpdatePointer highlights the item under a moving cursor, selects a clicked or tapped item and scrolls with the mouse wheel
+func (m *Menu) UpdatePointer(g *Game) error {
+	if pt, moved := cursorMoved(); moved {
+		if item := m.itemAt(pt); item != nil && item.Enabled() {
+			m.setActive(item)
+		}
+	}
+	switch steps := wheelSteps(); {
+	case steps > 0:
+		m.Next()
+	case steps < 0:
+		m.Prev()
+	}
+	if pt, ok := pointerJustPressed(); ok {
+		if item := m.itemAt(pt); item != nil && item.Enabled() {
+			m.setActive(item)
+			return m.Select(g)
+		}
+	}
+	return nil
 }
 
-// Select changes game mode according to active MenuItem selected
-func (m *Menu) Select() string {
-	log.Printf("Selecting " + m.active.option)
-	return m.active.option
+// hitRect gives the area of text drawn centered on (x, y), padded to fill a row of height rowH
+func hitRect(g *Game, text string, x, y, rowH int) image.Rectangle {
+	w := g.txtRenderer.SelectionRect(text).Width.Ceil()
+	return image.Rect(x-w/2-10, y-rowH/2, x+w/2+10, y+rowH/2)
 }
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+func TestMenuSkipsDisabledItems(t *testing.T) {
+	selected := ""
+	pick := func(name string) func(g *Game) error {
+		return func(g *Game) error {
+			selected = name
+			return nil
+		}
+	}
+	off := func() bool { return false }
+	m := NewMenu([]*MenuItem{
+		{option: "Hidden", action: pick("Hidden"), enabled: off},
+		NewMenuItem("First", pick("First")),
+		{option: "Skipped", action: pick("Skipped"), enabled: off},
+		NewMenuItem("Last", pick("Last")),
+	})
+
+	if m.active.option != "First" {
+		t.Fatalf("active = %q, want first enabled item", m.active.option)
+	}
+	m.Next()
+	if m.active.option != "Last" {
+		t.Errorf("Next landed on %q, want Last", m.active.option)
+	}
+	m.Next()
+	if m.active.option != "First" {
+		t.Errorf("Next wrapped to %q, want First", m.active.option)
+	}
+	m.Prev()
+	if err := m.Select(nil); err != nil || selected != "Last" {
+		t.Errorf("Select ran %q (err %v), want Last", selected, err)
+	}
+}
+
+func TestMenuItemValueAndAdjust(t *testing.T) {
+	volume := 5
+	m := NewMenu([]*MenuItem{{
+		option: "Volume",
+		value:  func() string { return string(rune('0' + volume)) },
+		adjust: func(g *Game, d int) { volume += d },
+	}})
+	m.Adjust(nil, 1)
+	if got := m.active.Label(); got != "Volume: < 6 >" {
+		t.Errorf("Label = %q", got)
+	}
+}
+
+func TestMenuStackKeepsRoot(t *testing.T) {
+	root := newPage("Root", nil)
+	sub := newPage("Sub", []string{"text"})
+	s := NewMenuStack(root)
+
+	s.Push(sub)
+	if s.Top() != sub || s.Len() != 2 {
+		t.Fatalf("Push: top %q, len %d", s.Top().header, s.Len())
+	}
+	s.Pop()
+	s.Pop()
+	if s.Top() != root || s.Len() != 1 {
+		t.Errorf("Pop removed the root menu: top %q, len %d", s.Top().header, s.Len())
+	}
+
+	s.Push(sub)
+	s.Push(newPage("Deeper", nil))
+	s.Reset()
+	if s.Top() != root {
+		t.Errorf("Reset left %q on top", s.Top().header)
+	}
+}
+
+func TestMenuItemAt(t *testing.T) {
+	m := NewMenu([]*MenuItem{NewMenuItem("Top", nil), NewMenuItem("Bottom", nil)})
+	m.head.rect = image.Rect(100, 100, 200, 150)
+	m.tail.rect = image.Rect(100, 150, 200, 200)
+
+	if item := m.itemAt(image.Pt(150, 170)); item != m.tail {
+		t.Errorf("itemAt(150,170) = %v, want Bottom", item)
+	}
+	if item := m.itemAt(image.Pt(50, 120)); item != nil {
+		t.Errorf("itemAt outside every item = %q", item.option)
+	}
+}
+
+func TestMenuScrollKeepsActiveVisible(t *testing.T) {
+	var items []*MenuItem
+	for _, o := range []string{"A", "B", "C", "D", "E", "F", "G", "H"} {
+		items = append(items, NewMenuItem(o, nil))
+	}
+	items[7].enabled = func() bool { return false }
+	m := NewMenu(items)
+	m.setVisible(3)
+
+	for i := 0; i < 4; i++ {
+		m.Next()
+	}
+	first, count, above, below := m.window()
+	if m.active.option != "E" || first.option != "C" || count != 3 || !above || !below {
+		t.Errorf("after 4 Next: active %q, window %q+%d above=%v below=%v", m.active.option, first.option, count, above, below)
+	}
+
+	m.PageDown()
+	if m.active.option != "G" || m.offset != 4 {
+		t.Errorf("PageDown stopped at %q offset %d, want last enabled item G at offset 4", m.active.option, m.offset)
+	}
+	m.PageUp()
+	m.PageUp()
+	m.PageUp()
+	if m.active.option != "A" || m.offset != 0 {
+		t.Errorf("PageUp stopped at %q offset %d, want A at offset 0", m.active.option, m.offset)
+	}
+
+	m.Prev()
+	if m.active.option != "G" || m.offset != 4 {
+		t.Errorf("Prev wrapped to %q offset %d, want G at offset 4", m.active.option, m.offset)
+	}
+}
+
+func TestSlotMenuDeleteDisarmsWhenMovingAway(t *testing.T) {
+	saveStore = NewMemoryStore()
+	if err := (&SaveData{Name: "Mona", Lives: 3}).Save(); err != nil {
+		t.Fatalf("saving: %v", err)
+	}
+	g := &Game{}
+	m := newSlotMenu(1, 3)
+	for m.active.option != "Delete" {
+		m.Next()
+	}
+	if err := m.Select(g); err != nil || !slotUsed(1) {
+		t.Fatalf("first Delete erased the save (err %v)", err)
+	}
+
+	m.Prev()
+	m.Next()
+	if err := m.Select(g); err != nil || !slotUsed(1) {
+		t.Errorf("Delete after moving away and back erased the save (err %v), want it asking again", err)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"image"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Planet describes one world the player can travel to: its map art, navigation bounds and levels
+type Planet struct {
+	Name     string
+	Image    string
+	Radius   float64
+	ViewMinX int
+	ViewMinY int
+	Levels   string // data file listing the levels on this planet
+	Map      string // data file describing map nodes and paths
+	Unlock   int    // levels to complete on this planet before the portal to the next one opens
+	PortalX  int
+	PortalY  int
+
+	image    *ebiten.Image
+	levels   []*LevelData
+	worldMap *WorldMap
+}
+
+func loadPlanets(fs embed.FS) []*Planet {
+	planets := readPlanets(fs)
+	for _, p := range planets {
+		p.image = loadImage(fs, p.Image)
+		p.levels = loadLevels(fs, p.Levels)
+		p.worldMap = loadWorldMap(fs, p.Map)
+	}
+	return planets
+}
+
+// readPlanets reads worlds.json, leaving the planets' art, levels and maps to be loaded
+func readPlanets(fs embed.FS) []*Planet {
+	var planets []*Planet
+	planetContent, err := fs.ReadFile("worlds.json")
+	if err != nil {
+		log.Fatal("Error when opening file: ", err)
+	}
+
+	err = json.Unmarshal(planetContent, &planets)
+	if err != nil {
+		log.Fatal("Error during Unmarshalling: ", err)
+	}
+	return planets
+}
+
+func loadLevels(fs embed.FS, file string) []*LevelData {
+	var levels []*LevelData
+	lvlContent, err := fs.ReadFile(file)
+	if err != nil {
+		log.Fatal("Error when opening file: ", err)
+	}
+
+	err = json.Unmarshal(lvlContent, &levels)
+	if err != nil {
+		log.Fatal("Error during Unmarshalling: ", err)
+	}
+
+	for _, l := range levels {
+		l.icon = levelImages[l.Name][0]
+		l.iconComplete = levelImages[l.Name][1]
+		l.background = levelImages[l.Name][2]
+	}
+	return levels
+}
+
+// completed counts the levels on the planet that have been completed
+func (p *Planet) completed() int {
+	count := 0
+	for _, l := range p.levels {
+		if l.Complete {
+			count++
+		}
+	}
+	return count
+}
+
+// portalBox is the area of the travel portal on screen, given the current world view
+func (p *Planet) portalBox(view *Viewer) image.Rectangle {
+	return image.Rect(p.PortalX+view.xCoord, p.PortalY+view.yCoord, p.PortalX+portalWidth+view.xCoord, p.PortalY+portalHeight+view.yCoord)
+}
+
+// arrival is where the avatar stands after stepping out of the travel portal, in World image coordinates
+func (p *Planet) arrival() (int, int) {
+	return p.PortalX + (portalWidth-worldCharWidth)/2, p.PortalY + portalHeight - worldCharHeight - 1
+}
+
+// center gives the middle of the planet's map art, which the avatar's movement radius is measured from
+func (p *Planet) center() (int, int) {
+	w, h := p.image.Size()
+	return w / 2, h / 2
+}
+
+// countLevels counts the levels across planets from their level lists, without loading any art
+func countLevels(fs embed.FS) int {
+	count := 0
+	for _, p := range readPlanets(fs) {
+		count += len(loadLevels(fs, p.Levels))
+	}
+	return count
+}
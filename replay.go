@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/mroobit/untitled-sidescroller/sim"
+)
+
+const (
+	// lastReplayFile holds the most recent attempt at a level, overwritten by each new one
+	lastReplayFile = "last.replay"
+
+	// replayFlushTicks is how often an attempt in progress is written out, so a crash loses little of it
+	replayFlushTicks = 300
+)
+
+var (
+	// gameVersion is recorded in replays; release builds set it with -ldflags "-X main.gameVersion=..."
+	gameVersion = "dev"
+
+	replayStore SaveStore = NewMemoryStore()
+
+	// pendingReplay is a replay given on the command line, started once loading finishes
+	pendingReplay *sim.Replay
+)
+
+// readReplayFile reads a replay for --replay
+func readReplayFile(path string) (*sim.Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	replay, err := sim.ReadReplay(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if replay.Version != gameVersion {
+		log.Printf("Replay was recorded by version %s, this is %s; it may not play back the same", replay.Version, gameVersion)
+	}
+	return replay, nil
+}
+
+// saveReplay writes a recorded attempt to lastReplayFile
+func saveReplay(replay *sim.Replay) {
+	var buf bytes.Buffer
+	if _, err := replay.WriteTo(&buf); err != nil {
+		log.Printf("Error encoding replay: %v", err)
+		return
+	}
+	if err := replayStore.Save(lastReplayFile, buf.Bytes()); err != nil {
+		log.Printf("Error saving replay: %v", err)
+		return
+	}
+	log.Printf("Saved %d ticks of %s to %s", len(replay.Inputs), replay.Level, lastReplayFile)
+}
+
+// startReplay plays a replay over the Title screen, with a throwaway session, returning to Title when it ends
+func startReplay(g *Game, replay *sim.Replay) error {
+	var data *LevelData
+	for _, p := range readPlanets(FileSystem) {
+		for _, l := range loadLevels(FileSystem, p.Levels) {
+			if l.Name == replay.Level {
+				data = l
+			}
+		}
+	}
+	if data == nil {
+		g.setNotice(fmt.Sprintf("Replay level %q not found", replay.Level))
+		return nil
+	}
+	log.Printf("Replaying %d ticks of %s", len(replay.Inputs), replay.Level)
+	g.session = NewSession("Replay")
+	level := sim.NewLevel(data.spec(), g.session.player, replay.Seed)
+	g.push(NewReplayPlay(data, level, replay))
+	return nil
+}
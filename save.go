@@ -1,49 +1,89 @@
 package main
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
-	"os"
-	"strings"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	saveSlots = 3
+
+	// currentSaveVersion is the format written by Save. Bump it, and add a migration, only when older documents need rewriting;
+	// new fields that older saves can do without, like Suspended, SavedAt and Checksum, don't need a new version.
+	currentSaveVersion = 2
 )
 
 var (
-	lastSaveState *SaveData
+	legacySaveDir                   = "./save/"
+	saveStore     SaveStore         = NewFileStore(legacySaveDir)
+	slotPreviews  map[int]*SaveData // saves listed in the Load Game menu, by slot
+
+	errSlotsFull = errors.New("all save slots are full, delete one from Load Game")
+	errNewerSave = errors.New("save is from a newer version of the game")
+
+	// saveMigrations[i] upgrades a save document from version i to version i+1
+	saveMigrations = []func(doc map[string]interface{}) error{
+		migrateSaveV0,
+		migrateSaveV1,
+	}
+
+	// saveKey signs save files. It only has to keep casual edits out of playtest data, not stop a determined player.
+	saveKey = []byte("untitled-sidescroller/save/v1")
 )
 
 // SaveData holds minimal data to recreate game state at most recent save
 type SaveData struct {
-	filename   string
+	slot       int
+	Version    int
 	Name       string
 	Lives      int
 	Score      int
 	Count      int
+	Seed       int64           `json:",string"` // seeds the game's levels; a string, since JSON numbers lose precision past 2^53
 	Complete   map[string]bool // which levels have been completed
+	World      string          // which planet the player is on
 	WorldCharX int
 	WorldCharY int
 	WorldViewX int
 	WorldViewY int
+	Suspended  *LevelSnapshot `json:",omitempty"` // level in progress when the game was suspended
+	SavedAt    time.Time
+	Modified   bool   `json:",omitempty"` // edited outside the game at some point, so kept off high score tables
+	Checksum   string `json:",omitempty"` // HMAC of everything else in the save file
 }
 
 // NewSaveData creates a new SaveData struct to hold game state
 func NewSaveData(g *Game) *SaveData {
 	log.Printf("Creating new SaveData")
+	s := g.session
 	saveData := &SaveData{
-		filename:   "",
-		Name:       playerChar.name,
-		Lives:      playerChar.lives,
-		Score:      g.score,
-		Count:      g.count,
+		slot:       s.slot,
+		Version:    currentSaveVersion,
+		Modified:   s.modified,
+		Name:       s.player.Name,
+		Lives:      s.player.Lives,
+		Score:      s.score,
+		Count:      s.playTime,
+		Seed:       s.seed,
 		Complete:   map[string]bool{},
-		WorldCharX: worldPlayer.xCoord,
-		WorldCharY: worldPlayer.yCoord,
-		WorldViewX: worldPlayer.view.xCoord,
-		WorldViewY: worldPlayer.view.yCoord,
+		WorldCharX: s.avatar.xCoord,
+		WorldCharY: s.avatar.yCoord,
+		WorldViewX: s.avatar.view.xCoord,
+		WorldViewY: s.avatar.view.yCoord,
 	}
 	var levels []*LevelData
-	switch world := g.state["World"].(type) {
-	case *World:
-		levels = world.levels
+	if world, ok := findState[*World](g); ok {
+		levels = world.allLevels()
+		saveData.World = world.planet.Name
 	}
 	for _, level := range levels {
 		if level.Complete == true {
@@ -53,53 +93,327 @@ func NewSaveData(g *Game) *SaveData {
 	return saveData
 }
 
-// Initialize applies base data for game state
-func (s *SaveData) Initialize(name string) {
-	s.filename = s.Name + ".json"
+// sameProgress reports whether two saves hold the same progress, ignoring play time and map location
+func (s *SaveData) sameProgress(o *SaveData) bool {
+	if s.Name != o.Name || s.Lives != o.Lives || s.Score != o.Score || s.World != o.World || len(s.Complete) != len(o.Complete) {
+		return false
+	}
+	for level, done := range s.Complete {
+		if o.Complete[level] != done {
+			return false
+		}
+	}
+	return true
 }
 
-// LoadGame takes game state data from a save file and stores it in active memory to use as game start point for play session
-func LoadGame(savefile string) *SaveData {
-	var gameData *SaveData
-	saveData, err := os.ReadFile("./save/" + savefile)
+// defaultSaveDir is the save directory inside the game's config directory
+func defaultSaveDir() string {
+	return filepath.Join(defaultConfigDir(), "save")
+}
+
+// prepareSaveStore keeps saves in dir from now on, bringing over saves left in ./save/ by older versions
+func prepareSaveStore(dir string) {
+	log.Printf("Using save directory %s", dir)
+	saveStore = NewFileStore(dir)
+	if !sameDir(dir, legacySaveDir) {
+		migrateLegacySaves(NewFileStore(legacySaveDir))
+	}
+}
+
+// migrateLegacySaves copies saves from an old store into free slots, marking each original as migrated
+func migrateLegacySaves(legacy SaveStore) {
+	names, err := legacy.List()
+	if err != nil {
+		return
+	}
+	for _, name := range names {
+		if filepath.Ext(name) != ".json" {
+			continue
+		}
+		data, err := legacy.Load(name)
+		if err != nil {
+			log.Printf("Error reading legacy save %s: %v", name, err)
+			continue
+		}
+		slot := slotFromFile(name)
+		if slot == 0 || slotUsed(slot) {
+			slot, err = firstFreeSlot()
+			if err != nil {
+				log.Printf("No free slot for legacy save %s: %v", name, err)
+				return
+			}
+		}
+		if err := writeSlot(slot, data); err != nil {
+			continue
+		}
+		log.Printf("Migrated legacy save %s to slot %d", name, slot)
+		if err := legacy.Save(name+".migrated", data); err == nil {
+			legacy.Delete(name)
+		}
+	}
+}
+
+func sameDir(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// LoadGame takes game state data from a save slot and stores it in active memory to use as game start point for play session
+func LoadGame(slot int) (*SaveData, error) {
+	saveData, err := saveStore.Load(slotFile(slot))
 	if err != nil {
-		log.Fatalf("Error when opening file %s: %v", savefile, err)
+		log.Printf("Error when opening save slot %d: %v", slot, err)
+		return nil, err
 	}
 
-	err = json.Unmarshal(saveData, &gameData)
+	gameData, err := decodeSave(saveData)
 	if err != nil {
-		log.Fatalf("Error when unmarshalling save data from %s: %v", savefile, err)
+		log.Printf("Error when decoding save data from slot %d: %v", slot, err)
+		return nil, err
+	}
+	gameData.slot = slot
+	if gameData.SavedAt.IsZero() {
+		if modTime, err := saveStore.ModTime(slotFile(slot)); err == nil {
+			gameData.SavedAt = modTime
+		}
 	}
 
-	return gameData
+	return gameData, nil
 	// later complexity: prompt display loading progress bar
 }
 
-// Save takes current game state and put it into SaveData and writes to hard memory
-func (s *SaveData) Save(g *Game, p *Character, w *WorldChar) {
+// decodeSave unmarshals a save document, migrating it from older save versions as needed
+func decodeSave(saveData []byte) (*SaveData, error) {
+	var doc map[string]interface{}
+	err := json.Unmarshal(saveData, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	valid := verifySave(doc)
+	_, signed := doc["Checksum"]
+	version := 0
+	if v, ok := doc["Version"].(float64); ok {
+		version = int(v)
+	}
+	// saves from before checksums can't be verified, but aren't tampered with for lacking one; the next save signs them
+	tampered := !valid && (signed || version == currentSaveVersion)
+	if version > currentSaveVersion {
+		return nil, fmt.Errorf("%w (save version %d, this game reads up to %d)", errNewerSave, version, currentSaveVersion)
+	}
+	for ; version < currentSaveVersion; version++ {
+		log.Printf("Migrating save from version %d to %d", version, version+1)
+		err = saveMigrations[version](doc)
+		if err != nil {
+			return nil, fmt.Errorf("migrating save from version %d: %w", version, err)
+		}
+		doc["Version"] = version + 1
+	}
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var gameData *SaveData
+	err = json.Unmarshal(migrated, &gameData)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case tampered:
+		log.Printf("Save checksum does not match, marking save as modified")
+		gameData.Modified = true
+	case !valid:
+		log.Printf("Save from before checksums, it will be signed when next saved")
+	}
+	return gameData, nil
+}
+
+// saveChecksum gives the HMAC of a save document, leaving out its Checksum.
+// Documents are signed as generic maps, which marshal with sorted keys, so checksums hold up across versions and field order.
+func saveChecksum(doc map[string]interface{}) (string, error) {
+	unsigned := map[string]interface{}{}
+	for k, v := range doc {
+		if k != "Checksum" {
+			unsigned[k] = v
+		}
+	}
+	canonical, err := json.Marshal(unsigned)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, saveKey)
+	mac.Write(canonical)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// verifySave reports whether a save document carries a checksum matching its contents
+func verifySave(doc map[string]interface{}) bool {
+	sum, ok := doc["Checksum"].(string)
+	if !ok {
+		return false
+	}
+	want, err := saveChecksum(doc)
+	return err == nil && hmac.Equal([]byte(sum), []byte(want))
+}
+
+// signSave adds a checksum to a marshalled save
+func signSave(save []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	err := json.Unmarshal(save, &doc)
+	if err != nil {
+		return nil, err
+	}
+	doc["Checksum"], err = saveChecksum(doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// migrateSaveV0 upgrades saves from before planets were added: everyone was on Planet Yorp
+func migrateSaveV0(doc map[string]interface{}) error {
+	if _, ok := doc["World"]; !ok {
+		doc["World"] = "Planet Yorp"
+	}
+	return nil
+}
+
+// migrateSaveV1 upgrades saves from before seeded levels, seeding from the character's name so the seed stays the same until the game is next saved
+func migrateSaveV1(doc map[string]interface{}) error {
+	name, _ := doc["Name"].(string)
+	doc["Seed"] = strconv.FormatInt(stringSeed(name), 10)
+	return nil
+}
+
+// Save writes SaveData to its slot, assigning the first free slot if it has none yet
+func (s *SaveData) Save() error {
 	log.Printf("Preparing data to save")
-	s.filename = strings.ToLower(s.Name) + "0.json"
+	s.Version = currentSaveVersion
+	s.SavedAt = time.Now()
+	if s.slot == 0 {
+		slot, err := firstFreeSlot()
+		if err != nil {
+			return err
+		}
+		s.slot = slot
+	}
 
+	s.Checksum = ""
 	save, err := json.Marshal(s)
+	if err == nil {
+		save, err = signSave(save)
+	}
 	if err != nil {
-		log.Fatalf("Error marshalling save data: %v\n", err)
+		log.Printf("Error marshalling save data: %v\n", err)
+		return err
 	}
 
-	log.Printf("Writing data to savefile")
+	return writeSlot(s.slot, save)
+}
+
+func writeSlot(slot int, data []byte) error {
+	log.Printf("Writing data to save slot %d", slot)
+	err := saveStore.Save(slotFile(slot), data)
+	if err != nil {
+		log.Printf("Error writing save slot %d: %v\n", slot, err)
+	}
+	return err
+}
 
-	f, err := os.Create("./save/" + s.filename)
+// CopySave duplicates the save in a slot into the first free slot, returning the new slot
+func CopySave(slot int) (int, error) {
+	log.Printf("Copying save slot %d", slot)
+	data, err := saveStore.Load(slotFile(slot))
+	if err != nil {
+		return 0, err
+	}
+	to, err := firstFreeSlot()
+	if err != nil {
+		return 0, err
+	}
+	return to, writeSlot(to, data)
+}
 
+// RenameSave changes the character name of the save in a slot
+func RenameSave(slot int, name string) error {
+	log.Printf("Renaming save slot %d to %s", slot, name)
+	gameData, err := LoadGame(slot)
 	if err != nil {
-		log.Fatalf("Error creating save file %s: %v\n", s.filename, err)
+		return err
 	}
+	gameData.Name = name
+	return gameData.Save()
+}
 
-	_, err = f.Write(save)
+// DeleteSave removes the save file in a slot
+func DeleteSave(slot int) error {
+	log.Printf("Deleting save slot %d", slot)
+	err := saveStore.Delete(slotFile(slot))
 	if err != nil {
-		log.Fatalf("Error writing to file %s: %v\n", s.filename, err)
+		log.Printf("Error deleting save slot %d: %v", slot, err)
 	}
+	return err
+}
+
+func slotFile(slot int) string {
+	return fmt.Sprintf("slot%d.json", slot)
+}
+
+// slotFromOption reads the slot number from a Load Game menu option
+func slotFromOption(option string) int {
+	slot := 0
+	fmt.Sscanf(option, "Slot %d", &slot)
+	return slot
+}
+
+// slotFromFile reads the slot number from a save file name, or 0 if it isn't a slot file
+func slotFromFile(name string) int {
+	slot := 0
+	fmt.Sscanf(name, "slot%d.json", &slot)
+	if slot < 1 || slot > saveSlots || slotFile(slot) != name {
+		return 0
+	}
+	return slot
+}
 
-	saveString := string(save)
-	log.Printf(saveString)
-	//r later complexity: prompt display saving progress bar
-	// prompt display confirmation/failure
+func slotUsed(slot int) bool {
+	return storeHas(saveStore, slotFile(slot))
+}
+
+func firstFreeSlot() (int, error) {
+	for slot := 1; slot <= saveSlots; slot++ {
+		if !slotUsed(slot) {
+			return slot, nil
+		}
+	}
+	return 0, errSlotsFull
+}
+
+// preview summarizes a save for the Load Game menu: progress, score, lives, play time and when it was saved
+func (s *SaveData) preview(totalLevels int) string {
+	return fmt.Sprintf("%d/%d levels  Score %d  Lives %d  %s  %s",
+		len(s.Complete), totalLevels, s.Score, s.Lives, playTime(s.Count), s.SavedAt.Format("2006-01-02 15:04"))
+}
+
+// highScores lists the best scores among listed saves, leaving out saves that were modified outside the game
+func highScores() []string {
+	var saves []*SaveData
+	for _, s := range slotPreviews {
+		if !s.Modified {
+			saves = append(saves, s)
+		}
+	}
+	sort.Slice(saves, func(i, j int) bool {
+		return saves[i].Score > saves[j].Score
+	})
+	scores := []string{}
+	for _, s := range saves {
+		scores = append(scores, fmt.Sprintf("%-12s %6d", s.Name, s.Score))
+	}
+	if len(scores) == 0 {
+		scores = append(scores, "No high scores yet")
+	}
+	return scores
 }
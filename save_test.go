@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func loadFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("reading fixture %s: %v", name, err)
+	}
+	return data
+}
+
+func TestSaveMigrationsCoverEveryVersion(t *testing.T) {
+	if len(saveMigrations) != currentSaveVersion {
+		t.Fatalf("have %d save migrations for save version %d", len(saveMigrations), currentSaveVersion)
+	}
+}
+
+func TestDecodeSaveMigratesV0(t *testing.T) {
+	gameData, err := decodeSave(loadFixture(t, "save-v0.json"))
+	if err != nil {
+		t.Fatalf("decoding v0 save: %v", err)
+	}
+	if gameData.Version != currentSaveVersion {
+		t.Errorf("Version = %d, want %d", gameData.Version, currentSaveVersion)
+	}
+	if gameData.World != "Planet Yorp" {
+		t.Errorf("World = %q, want Planet Yorp", gameData.World)
+	}
+	if gameData.Name != "Mona" || gameData.Lives != 3 || gameData.Score != 40 || gameData.Count != 5400 {
+		t.Errorf("progress not carried over: %+v", gameData)
+	}
+	if !gameData.Complete["Goo Alley"] {
+		t.Errorf("Complete = %v, want Goo Alley complete", gameData.Complete)
+	}
+	if gameData.WorldViewX != -400 || gameData.WorldViewY != -500 {
+		t.Errorf("world view = %d,%d, want -400,-500", gameData.WorldViewX, gameData.WorldViewY)
+	}
+	if gameData.Modified {
+		t.Errorf("Modified = true, want false for a save from before checksums")
+	}
+	if gameData.Seed != stringSeed("Mona") {
+		t.Errorf("Seed = %d, want one from the name", gameData.Seed)
+	}
+}
+
+func TestDecodeSaveMigratesV1(t *testing.T) {
+	gameData, err := decodeSave(loadFixture(t, "save-v1.json"))
+	if err != nil {
+		t.Fatalf("decoding v1 save: %v", err)
+	}
+	if gameData.Version != currentSaveVersion {
+		t.Errorf("Version = %d, want %d", gameData.Version, currentSaveVersion)
+	}
+	if gameData.World != "Vorp Minor" {
+		t.Errorf("World = %q, want Vorp Minor", gameData.World)
+	}
+	if len(gameData.Complete) != 2 || gameData.Score != 120 {
+		t.Errorf("progress not carried over: %+v", gameData)
+	}
+	if gameData.Suspended != nil {
+		t.Errorf("Suspended = %+v, want nil", gameData.Suspended)
+	}
+}
+
+func TestDecodeSaveSuspendedLevel(t *testing.T) {
+	gameData, err := decodeSave(loadFixture(t, "save-v1-suspended.json"))
+	if err != nil {
+		t.Fatalf("decoding suspended v1 save: %v", err)
+	}
+	snap := gameData.Suspended
+	if snap == nil {
+		t.Fatal("Suspended = nil, want Yikesful Mountain snapshot")
+	}
+	if snap.Level != "Yikesful Mountain" || !snap.Gem || snap.Player.X != 290 {
+		t.Errorf("snapshot not carried over: %+v", snap)
+	}
+	if len(snap.Creatures) != 1 || snap.Creatures[0].PauseCtr != 31 {
+		t.Errorf("Creatures = %+v, want one paused teen yorp", snap.Creatures)
+	}
+}
+
+func TestDecodeSaveRejectsNewerVersion(t *testing.T) {
+	_, err := decodeSave(loadFixture(t, "save-newer.json"))
+	if !errors.Is(err, errNewerSave) {
+		t.Fatalf("err = %v, want errNewerSave", err)
+	}
+}
+
+func TestSavePreview(t *testing.T) {
+	s := &SaveData{
+		Name:     "Mona",
+		Lives:    2,
+		Score:    120,
+		Count:    3723 * 60,
+		Complete: map[string]bool{"Goo Alley": true, "Yikesful Mountain": true},
+		SavedAt:  time.Date(2026, 10, 19, 14, 5, 0, 0, time.Local),
+	}
+	want := "2/3 levels  Score 120  Lives 2  1:02:03  2026-10-19 14:05"
+	if got := s.preview(3); got != want {
+		t.Errorf("preview() = %q, want %q", got, want)
+	}
+}
+
+func TestMigrateLegacySaves(t *testing.T) {
+	legacy := NewMemoryStore()
+	saveStore = NewMemoryStore()
+	for _, name := range []string{"mona0.json", "slot2.json"} {
+		legacy.Save(name, loadFixture(t, "save-v0.json"))
+	}
+
+	migrateLegacySaves(legacy)
+
+	for slot := 1; slot <= 2; slot++ {
+		if !slotUsed(slot) {
+			t.Errorf("slot %d not migrated", slot)
+		}
+	}
+	names, _ := legacy.List()
+	want := []string{"mona0.json.migrated", "slot2.json.migrated"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("legacy saves = %v, want %v", names, want)
+	}
+}
+
+func TestSaveLoadCopyDelete(t *testing.T) {
+	saveStore = NewMemoryStore()
+	s := &SaveData{Name: "Mona", Lives: 3, Score: 40, Seed: 1<<62 + 1, Complete: map[string]bool{"Goo Alley": true}, World: "Planet Yorp"}
+	if err := s.Save(); err != nil {
+		t.Fatalf("saving: %v", err)
+	}
+	if s.slot != 1 {
+		t.Fatalf("saved to slot %d, want first free slot 1", s.slot)
+	}
+
+	loaded, err := LoadGame(1)
+	if err != nil {
+		t.Fatalf("loading: %v", err)
+	}
+	if !loaded.sameProgress(s) || loaded.Version != currentSaveVersion || loaded.SavedAt.IsZero() || loaded.Modified {
+		t.Errorf("loaded %+v, want %+v", loaded, s)
+	}
+	if loaded.Seed != s.Seed {
+		t.Errorf("loaded seed %d, want %d", loaded.Seed, s.Seed)
+	}
+
+	to, err := CopySave(1)
+	if err != nil || to != 2 {
+		t.Fatalf("CopySave = %d, %v, want slot 2", to, err)
+	}
+	if err := DeleteSave(1); err != nil {
+		t.Fatalf("deleting: %v", err)
+	}
+	if slotUsed(1) || !slotUsed(2) {
+		t.Errorf("slots used after delete: 1=%v 2=%v, want only 2", slotUsed(1), slotUsed(2))
+	}
+}
+
+func TestRenameSave(t *testing.T) {
+	saveStore = NewMemoryStore()
+	s := &SaveData{Name: "Mona", Lives: 3, Score: 40, World: "Planet Yorp"}
+	if err := s.Save(); err != nil {
+		t.Fatalf("saving: %v", err)
+	}
+	if err := RenameSave(s.slot, "Zorp"); err != nil {
+		t.Fatalf("renaming: %v", err)
+	}
+	loaded, err := LoadGame(s.slot)
+	if err != nil {
+		t.Fatalf("loading: %v", err)
+	}
+	if loaded.Name != "Zorp" || loaded.Score != 40 || loaded.Modified {
+		t.Errorf("renamed save = %+v, want Zorp with the same progress", loaded)
+	}
+}
+
+func TestSaveFailsWhenSlotsFull(t *testing.T) {
+	saveStore = NewMemoryStore()
+	for slot := 1; slot <= saveSlots; slot++ {
+		saveStore.Save(slotFile(slot), loadFixture(t, "save-v1.json"))
+	}
+	err := (&SaveData{Name: "Mona"}).Save()
+	if !errors.Is(err, errSlotsFull) {
+		t.Fatalf("err = %v, want errSlotsFull", err)
+	}
+}
+
+func TestEditedSaveIsModified(t *testing.T) {
+	saveStore = NewMemoryStore()
+	for _, name := range []string{"Mona", "Zed"} {
+		if err := (&SaveData{Name: name, Lives: 3, Score: 40, Complete: map[string]bool{}}).Save(); err != nil {
+			t.Fatalf("saving: %v", err)
+		}
+	}
+	data, _ := saveStore.Load(slotFile(2))
+	saveStore.Save(slotFile(2), bytes.Replace(data, []byte(`"Score":40`), []byte(`"Score":9999`), 1))
+
+	edited, err := LoadGame(2)
+	if err != nil {
+		t.Fatalf("loading edited save: %v", err)
+	}
+	if !edited.Modified || edited.Score != 9999 {
+		t.Errorf("edited save = %+v, want loadable and Modified", edited)
+	}
+
+	refreshLoadMenu(&Game{})
+	scores := highScores()
+	if len(scores) != 1 || !strings.HasPrefix(scores[0], "Mona") {
+		t.Errorf("highScores() = %q, want only Mona", scores)
+	}
+
+	// resaving a modified game keeps it marked, and the mark is covered by the checksum
+	edited.Save()
+	resaved, _ := LoadGame(2)
+	if !resaved.Modified {
+		t.Errorf("resaved Modified = false, want true")
+	}
+}
+
+func TestSaveWithoutChecksum(t *testing.T) {
+	saveStore = NewMemoryStore()
+	old, err := decodeSave(loadFixture(t, "save-v0.json"))
+	if err != nil {
+		t.Fatalf("decoding v0 save: %v", err)
+	}
+	if err := old.Save(); err != nil {
+		t.Fatalf("resaving: %v", err)
+	}
+	resaved, err := LoadGame(old.slot)
+	if err != nil || resaved.Modified || resaved.Checksum == "" {
+		t.Errorf("resaved old save = %+v, %v; want signed and not Modified", resaved, err)
+	}
+
+	var doc map[string]interface{}
+	data, _ := saveStore.Load(slotFile(old.slot))
+	json.Unmarshal(data, &doc)
+	delete(doc, "Checksum")
+	stripped, _ := json.Marshal(doc)
+	saveStore.Save(slotFile(old.slot), stripped)
+	if loaded, err := LoadGame(old.slot); err != nil || !loaded.Modified {
+		t.Errorf("current save with its checksum removed = %+v, %v; want Modified", loaded, err)
+	}
+}
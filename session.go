@@ -0,0 +1,66 @@
+package main
+
+import (
+	"hash/fnv"
+	"log"
+	"math/rand"
+
+	"github.com/mroobit/untitled-sidescroller/sim"
+)
+
+// Session is one game in progress: the player's character and World avatar, score, play time and save slot
+type Session struct {
+	player   *sim.Character
+	avatar   *WorldChar
+	score    int
+	playTime int // ticks since the game was started, carried across saves
+	slot     int
+	modified bool      // loaded from a save that was edited outside the game
+	seed     int64     // save seed, mixed with each level's name to seed that level
+	lastSave *SaveData // progress as of the last save or load, to tell whether there is anything unsaved
+}
+
+// seedOverride, set with --seed, seeds every level in place of the session's seed
+var seedOverride *int64
+
+// NewSession creates a Session for a new character, standing at the start of the World
+func NewSession(name string) *Session {
+	log.Printf("Starting new session for %s", name)
+	return &Session{
+		player: sim.NewCharacter(name, 100),
+		avatar: NewWorldChar(spriteSheet, NewViewer()),
+		seed:   rand.Int63(),
+	}
+}
+
+// NewSessionFromSave recreates the Session held in a save
+func NewSessionFromSave(s *SaveData) *Session {
+	session := NewSession(s.Name)
+	session.player.Lives = s.Lives
+	session.avatar.view.xCoord = s.WorldViewX
+	session.avatar.view.yCoord = s.WorldViewY
+	session.avatar.xCoord = s.WorldCharX
+	session.avatar.yCoord = s.WorldCharY
+	session.score = s.Score
+	session.playTime = s.Count
+	session.slot = s.slot
+	session.modified = s.Modified
+	session.seed = s.Seed
+	session.lastSave = s
+	return session
+}
+
+// levelSeed gives the seed for an attempt at a level: the same for every attempt in this save, and different between levels and saves
+func (s *Session) levelSeed(level string) int64 {
+	if seedOverride != nil {
+		return *seedOverride
+	}
+	return stringSeed(level) ^ s.seed
+}
+
+// stringSeed hashes a string into a seed
+func stringSeed(str string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(str))
+	return int64(h.Sum64())
+}
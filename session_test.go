@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestLevelSeed(t *testing.T) {
+	a, b := &Session{seed: 1}, &Session{seed: 2}
+	if a.levelSeed("Goo Alley") != a.levelSeed("Goo Alley") {
+		t.Error("the same level in the same save got different seeds")
+	}
+	if a.levelSeed("Goo Alley") == a.levelSeed("Blob Burrow") {
+		t.Error("two levels in one save share a seed")
+	}
+	if a.levelSeed("Goo Alley") == b.levelSeed("Goo Alley") {
+		t.Error("one level in two saves shares a seed")
+	}
+
+	seed := int64(50)
+	seedOverride = &seed
+	defer func() { seedOverride = nil }()
+	if got := b.levelSeed("Goo Alley"); got != seed {
+		t.Errorf("levelSeed with --seed = %d, want %d", got, seed)
+	}
+}
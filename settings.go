@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	settingsFile   = "settings.json"
+	maxVolume      = 10
+	maxWindowScale = 3
+)
+
+var (
+	settings                = defaultSettings()
+	settingsStore SaveStore = NewMemoryStore()
+
+	// languages the menus can be shown in; text is English-only until translations are added
+	languages = []string{"English"}
+)
+
+// Settings are the player's preferences, kept between runs in settings.json
+type Settings struct {
+	Volume      int // kept for when the game has sound; there is no audio yet
+	Fullscreen  bool
+	WindowScale int
+	VSync       bool
+	ShowFPS     bool
+	Language    string
+	Deadzone    int                     // percent of the gamepad stick's travel ignored around the centre
+	Bindings    map[Action][]ebiten.Key // keys for each action, saved by name
+}
+
+func defaultSettings() *Settings {
+	return &Settings{
+		Volume:      8,
+		WindowScale: 1,
+		VSync:       true,
+		Language:    languages[0],
+		Deadzone:    25,
+		Bindings:    defaultBindings(),
+	}
+}
+
+// defaultConfigDir is the game's folder in the user's config directory, falling back to the working directory where there is none
+func defaultConfigDir() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		log.Printf("No user config directory, using working directory: %v", err)
+		return "."
+	}
+	return filepath.Join(configDir, "untitled-sidescroller")
+}
+
+// loadSettings reads settings from a store, using defaults for a missing or damaged file
+func loadSettings(store SaveStore) *Settings {
+	s := defaultSettings()
+	data, err := store.Load(settingsFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("No settings yet, using defaults")
+		return s
+	}
+	if err == nil {
+		err = json.Unmarshal(data, s)
+	}
+	if err != nil {
+		log.Printf("Error reading settings, using defaults: %v", err)
+		return defaultSettings()
+	}
+	s.normalize()
+	return s
+}
+
+// normalize brings hand-edited values back into range, and gives unbound actions their default keys
+func (s *Settings) normalize() {
+	s.Volume = clamp(s.Volume, 0, maxVolume)
+	s.WindowScale = clamp(s.WindowScale, 1, maxWindowScale)
+	s.Deadzone = clamp(s.Deadzone, minDeadzone, maxDeadzone)
+	defaults := defaultBindings()
+	if s.Bindings == nil {
+		s.Bindings = defaults
+	}
+	for a := range s.Bindings {
+		if !containsAction(actions, a) {
+			delete(s.Bindings, a)
+		}
+	}
+	for _, a := range actions {
+		if len(s.Bindings[a]) == 0 {
+			s.Bindings[a] = defaults[a]
+		}
+	}
+	for _, l := range languages {
+		if l == s.Language {
+			return
+		}
+	}
+	s.Language = languages[0]
+}
+
+// save writes settings to settingsStore
+func (s *Settings) save() error {
+	data, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		return err
+	}
+	return settingsStore.Save(settingsFile, data)
+}
+
+// apply sets up the window and frame rate to match the settings
+func (s *Settings) apply() {
+	log.Printf("Applying settings: %+v", *s)
+	ebiten.SetWindowSize(winWidth*s.WindowScale, winHeight*s.WindowScale)
+	ebiten.SetFullscreen(s.Fullscreen)
+	if s.VSync {
+		ebiten.SetFPSMode(ebiten.FPSModeVsyncOn)
+	} else {
+		ebiten.SetFPSMode(ebiten.FPSModeVsyncOffMaximum)
+	}
+}
+
+// changed applies and saves settings after a change in the Settings menu
+func (s *Settings) changed(g *Game) {
+	s.apply()
+	if err := s.save(); err != nil {
+		g.setNotice("Could not save settings: " + err.Error())
+	}
+}
+
+// newSettingsMenu creates the Settings menu; left/right or Enter change the value of an option
+func newSettingsMenu() *Menu {
+	toggle := func(b *bool) func(g *Game, d int) {
+		return func(g *Game, d int) {
+			*b = !*b
+			settings.changed(g)
+		}
+	}
+	step := func(v *int, lo, hi int) func(g *Game, d int) {
+		return func(g *Game, d int) {
+			*v = clamp(*v+d, lo, hi)
+			settings.changed(g)
+		}
+	}
+	onOff := func(b *bool) func() string {
+		return func() string {
+			if *b {
+				return "On"
+			}
+			return "Off"
+		}
+	}
+	items := []*MenuItem{
+		{option: "Volume", description: "Music and sound effects", value: func() string { return fmt.Sprint(settings.Volume) },
+			adjust: step(&settings.Volume, 0, maxVolume)},
+		{option: "Fullscreen", value: onOff(&settings.Fullscreen), adjust: toggle(&settings.Fullscreen)},
+		{option: "Window Scale", description: "Size of the window when not fullscreen", value: func() string { return fmt.Sprintf("%dx", settings.WindowScale) },
+			adjust: step(&settings.WindowScale, 1, maxWindowScale)},
+		{option: "VSync", description: "Match the display's refresh rate", value: onOff(&settings.VSync), adjust: toggle(&settings.VSync)},
+		{option: "Stick Deadzone", description: "How far the gamepad stick moves before it counts", value: func() string { return fmt.Sprintf("%d%%", settings.Deadzone) },
+			adjust: func(g *Game, d int) {
+				settings.Deadzone = clamp(settings.Deadzone+d*deadzoneStep, minDeadzone, maxDeadzone)
+				settings.changed(g)
+			}},
+		{option: "Show FPS", description: "Frame rate, and the random seed during a level", value: onOff(&settings.ShowFPS), adjust: toggle(&settings.ShowFPS)},
+		{option: "Language", value: func() string { return settings.Language }, adjust: func(g *Game, d int) {
+			curr := 0
+			for i, l := range languages {
+				if l == settings.Language {
+					curr = i
+				}
+			}
+			settings.Language = languages[(curr+d+len(languages))%len(languages)]
+			settings.changed(g)
+		}},
+	}
+	for _, item := range items {
+		adjust := item.adjust
+		item.action = func(g *Game) error {
+			adjust(g, 1)
+			return nil
+		}
+	}
+	items = append(items,
+		&MenuItem{option: "Controls", description: "Change the keys for each action", action: showControls},
+		NewMenuItem("Back", popMenu))
+	m := NewMenu(items)
+	m.header = "Settings"
+	return m
+}
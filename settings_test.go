@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestLoadSettings(t *testing.T) {
+	store := NewMemoryStore()
+	if got := loadSettings(store); !reflect.DeepEqual(got, defaultSettings()) {
+		t.Errorf("missing settings = %+v, want defaults", got)
+	}
+
+	settingsStore = store
+	saved := &Settings{Volume: 3, Fullscreen: true, WindowScale: 2, ShowFPS: true, Language: "English", Deadzone: 40, Bindings: defaultBindings()}
+	saved.Bindings[actionJump] = []ebiten.Key{ebiten.KeyW, ebiten.KeySpace}
+	if err := saved.save(); err != nil {
+		t.Fatalf("saving settings: %v", err)
+	}
+	if got := loadSettings(store); !reflect.DeepEqual(got, saved) {
+		t.Errorf("loaded %+v, want %+v", got, saved)
+	}
+
+	store.Save(settingsFile, []byte(`{"Volume": 99, "WindowScale": 0, "Deadzone": 100, "Language": "Klingon", "Bindings": {"Jump": [], "Dance": ["D"]}}`))
+	got := loadSettings(store)
+	if got.Volume != maxVolume || got.WindowScale != 1 || got.Language != languages[0] || got.Deadzone != maxDeadzone {
+		t.Errorf("out of range settings = %+v, want them clamped", got)
+	}
+	if !reflect.DeepEqual(got.Bindings, defaultBindings()) {
+		t.Errorf("bindings = %v, want defaults for unbound actions and unknown actions dropped", got.Bindings)
+	}
+
+	store.Save(settingsFile, []byte(`{"WindowScale": `))
+	if got := loadSettings(store); !reflect.DeepEqual(got, defaultSettings()) {
+		t.Errorf("damaged settings = %+v, want defaults", got)
+	}
+}
@@ -0,0 +1,97 @@
+package sim
+
+// View is the offset of the visible part of a level, as the X,Y of its upper left corner
+type View struct {
+	X int
+	Y int
+}
+
+// Character is the player character's state
+type Character struct {
+	Name    string
+	View    View
+	Facing  int // sprite sheet row: 0 for right, PlayerHeight for left
+	X       int
+	Y       int
+	YVelo   int
+	Status  string // "ground", "jump", "fall", "dying" or "totally dead"
+	HP      int
+	HPTotal int
+	Lives   int
+	Walking bool
+}
+
+// NewCharacter creates new player character
+func NewCharacter(name string, hp int) *Character {
+	character := &Character{
+		Name:    name,
+		View:    View{0, ScreenHeight},
+		X:       20,
+		Y:       380,
+		YVelo:   Gravity,
+		Status:  "ground",
+		HP:      hp,
+		HPTotal: hp,
+		Lives:   4,
+	}
+	return character
+}
+
+func (c *Character) setLocation(x, y int) {
+	c.X = x
+	c.Y = y
+}
+
+func (c *Character) resetView(levelHeight int) {
+	c.View.X = 0
+	c.View.Y = ScreenHeight - levelHeight
+}
+
+func (c *Character) moveRight(tiles [][]int) {
+	c.Facing = 0
+	switch {
+	case c.View.X == 0 && c.X < 290:
+		c.X += 5
+	case c.View.X == -200 && c.X < 530:
+		c.X += 5
+	case c.View.X > -200:
+		c.View.X -= 5
+	}
+	side := (c.X - c.View.X + PlayerWidth + 1) / TileSize
+	top := (c.Y - c.View.Y) / TileSize
+	if solid(tiles, side, top) {
+		c.X -= 5
+	}
+}
+
+func (c *Character) moveLeft(tiles [][]int) {
+	c.Facing = PlayerHeight
+	switch {
+	case c.View.X == -200 && c.X > 290:
+		c.X -= 5
+	case c.View.X == 0 && c.X > 40:
+		c.X -= 5
+	case c.View.X < 0:
+		c.View.X += 5
+	}
+	side := (c.X - c.View.X) / TileSize
+	top := (c.Y - c.View.Y) / TileSize
+	if solid(tiles, side, top) {
+		c.X += 5
+	}
+}
+
+func (c *Character) jump(duration int) { // strength is keypress duration
+	switch {
+	case c.Status == "ground" && duration == 1:
+		c.Status = "jump"
+		c.YVelo = -Gravity
+	}
+}
+
+// Death takes a life and starts the dying animation
+func (c *Character) Death() {
+	c.HP = 0
+	c.Lives--
+	c.Status = "dying"
+}
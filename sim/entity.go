@@ -0,0 +1,112 @@
+package sim
+
+import (
+	"math/rand"
+)
+
+// TreasureKind describes what a treasure id in a layout stands for
+type TreasureKind struct {
+	Name  string
+	Value int
+	Gem   bool // collecting it opens the portal
+}
+
+// TreasureKinds are the treasures a layout can hold, by id
+var TreasureKinds = map[int]TreasureKind{
+	3: {"Portal Gem", 0, true},
+	4: {"Shiny Green Ball", 10, false},
+}
+
+// Brick is a solid tile, fixed in level coordinates
+type Brick struct {
+	X int
+	Y int
+}
+
+// Hazard is a fixed object that kills the player on contact
+type Hazard struct {
+	Name   string
+	X      int
+	Y      int
+	Damage int
+}
+
+// NewHazard creates a new Hazard within a level
+func NewHazard(name string, x int, y int, damage int) *Hazard {
+	return &Hazard{
+		Name:   name,
+		X:      x,
+		Y:      y,
+		Damage: damage,
+	}
+}
+
+// Creature is a wandering enemy that kills the player on contact
+type Creature struct {
+	Name        string
+	Facing      int // sprite sheet row: 0 for left, 50 for right
+	X           int
+	Y           int
+	HP          int
+	HPTotal     int
+	Damage      int
+	SeesChar    bool
+	MovementCtr int
+	PauseCtr    int
+}
+
+// NewCreature creates a new Creature within a level
+func NewCreature(name string, x int, y int, hp int, damage int) *Creature {
+	return &Creature{
+		Name:    name,
+		Facing:  50,
+		X:       x,
+		Y:       y,
+		HP:      hp,
+		HPTotal: hp,
+		Damage:  damage,
+	}
+}
+
+// move walks, pauses and turns the creature, or charges it at the player once it sees them; its choices come from the level's rng
+func (c *Creature) move(rng *rand.Rand) {
+	switch {
+	case c.MovementCtr > 0:
+		// keep moving same dir
+		c.MovementCtr--
+		if c.Facing == 0 && c.X <= 3 {
+			c.MovementCtr = 0
+		} else if c.Facing == 0 && c.X > 3 {
+			c.X -= 3
+		} else if c.Facing == 50 && c.X >= 597 {
+			c.MovementCtr = 0
+		} else if c.X < 597 {
+			c.X += 3
+		}
+	case c.SeesChar:
+		// rampage towards char
+		if c.Facing == 0 {
+			c.X -= 10
+		} else {
+			c.X += 10
+		}
+	case c.PauseCtr > 0:
+		// pause
+		if c.PauseCtr%9 == 0 {
+			c.Facing = rng.Intn(2) * 50
+		}
+		c.PauseCtr--
+	default:
+		// reset random
+		c.MovementCtr = rng.Intn(50) + 20
+		c.PauseCtr = rng.Intn(40) + 20
+		c.Facing = rng.Intn(2) * 50
+	}
+}
+
+// Treasure is an uncollected treasure of kind ID
+type Treasure struct {
+	ID int
+	X  int
+	Y  int
+}
@@ -0,0 +1,265 @@
+package sim
+
+import (
+	"image"
+	"math/rand"
+)
+
+// LevelSpec is what the simulation needs to know about a level: its layout, and where the player starts and leaves
+type LevelSpec struct {
+	Name    string
+	PlayerX int
+	PlayerY int
+	ExitX   int
+	ExitY   int
+	Height  int     // height of the level background
+	Layout  [][]int // layers of TileXCount-wide tiles: bricks, hazards, creatures, treasures
+}
+
+// Level is one attempt at a level, with its own copy of the layout and of everything in it, so levels never share state.
+// Hazards, creatures and treasures are kept in screen coordinates and move with the view; bricks are in level coordinates.
+type Level struct {
+	Spec      *LevelSpec
+	Player    *Character
+	Tiles     [][]int // copy of the layout; layer 0 is the bricks the player walks on
+	Bricks    []*Brick
+	Hazards   []*Hazard
+	Creatures []*Creature
+	Treasures []*Treasure
+	Gem       bool
+	Ticks     int
+	Seed      int64 // seed of rng, so an attempt can be replayed
+	src       *countingSource
+	rng       *rand.Rand
+}
+
+// countingSource is a rand.Source that counts its draws, so a level's randomness can be picked up where it left off
+type countingSource struct {
+	rand.Source
+	draws int64
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.Source.Int63()
+}
+
+// NewLevel starts player at the beginning of a level, standing at full health, with all randomness drawn from seed
+func NewLevel(spec *LevelSpec, player *Character, seed int64) *Level {
+	l := RestoreLevel(spec, player, spec.Layout, seed)
+	player.resetView(spec.Height)
+	player.setLocation(spec.PlayerX, spec.PlayerY)
+	player.HP = player.HPTotal
+	player.Status = "ground"
+	player.YVelo = Gravity
+	player.Facing = 0
+	player.Walking = false
+	l.populate(player.View.X, player.View.Y)
+	return l
+}
+
+// RestoreLevel creates a level with tiles and bricks in place, leaving the player and the lists of things to be filled in from a snapshot
+func RestoreLevel(spec *LevelSpec, player *Character, tiles [][]int, seed int64) *Level {
+	l := &Level{
+		Spec:   spec,
+		Player: player,
+		Tiles:  LayoutCopy(tiles),
+		Seed:   seed,
+		src:    &countingSource{Source: rand.NewSource(seed)},
+	}
+	l.rng = rand.New(l.src)
+	for i, h := range spec.Layout[0] {
+		if h == 1 {
+			l.Bricks = append(l.Bricks, &Brick{(i % TileXCount) * TileSize, (i / TileXCount) * TileSize})
+		}
+	}
+	return l
+}
+
+// Draws gives how many numbers the level's randomness has used, to keep in a snapshot
+func (l *Level) Draws() int64 {
+	return l.src.draws
+}
+
+// FastForward skips the level's randomness ahead to where a snapshot left it, draws numbers in
+func (l *Level) FastForward(draws int64) {
+	for l.src.draws < draws {
+		l.src.Int63()
+	}
+}
+
+// populate places the level's hazards, creatures and treasures on screen for a view offset by vsx, vsy
+func (l *Level) populate(vsx int, vsy int) {
+	for i, h := range l.Spec.Layout[1] {
+		x := (i%TileXCount)*TileSize - vsx
+		y := (i/TileXCount)*TileSize + vsy
+		if h == 5 {
+			l.Hazards = append(l.Hazards, NewHazard("blob", x, y, 100))
+		}
+	}
+	for i, h := range l.Spec.Layout[2] {
+		x := (i%TileXCount)*TileSize - vsx
+		y := (i/TileXCount)*TileSize + vsy
+		if h == 6 {
+			l.Creatures = append(l.Creatures, NewCreature("teen yorp", x, y, 100, 100))
+		}
+	}
+	for i, h := range l.Spec.Layout[3] {
+		x := (i%TileXCount)*TileSize - vsx
+		y := (i/TileXCount)*TileSize + vsy
+		if h > 0 {
+			l.Treasures = append(l.Treasures, &Treasure{h, x, y})
+		}
+	}
+}
+
+// scroll moves hazards, creatures and treasures on screen to follow a change in the view
+func (l *Level) scroll(dx, dy int) {
+	for _, h := range l.Hazards {
+		h.X += dx
+		h.Y += dy
+	}
+	for _, c := range l.Creatures {
+		c.X += dx
+		c.Y += dy
+	}
+	for _, t := range l.Treasures {
+		t.X += dx
+		t.Y += dy
+	}
+}
+
+// solid reports whether the tile at column x, row y of the level is a brick
+func (l *Level) solid(x, y int) bool {
+	return solid(l.Tiles, x, y)
+}
+
+// solid reports whether the tile at column x, row y of layer 0 is a brick; anything off the layout is open space
+func solid(tiles [][]int, x, y int) bool {
+	i := y*TileXCount + x
+	return x >= 0 && x < TileXCount && i >= 0 && i < len(tiles[0]) && tiles[0][i] == 1
+}
+
+// Tick advances the level by one tick: moving the player and creatures, collecting treasures, and checking for death and the exit
+func (l *Level) Tick(in Input) Result {
+	var result Result
+	pc := l.Player
+	l.Ticks++
+
+	baseView := pc.View
+	// 2 direction movement
+	pc.Walking = in.Left || in.Right
+	if in.Right {
+		pc.moveRight(l.Tiles)
+	}
+	if in.Left {
+		pc.moveLeft(l.Tiles)
+	}
+	// if view x changed, update x location of on-screen objects
+	if baseView.X != pc.View.X {
+		l.scroll(pc.View.X-baseView.X, 0)
+	}
+
+	if in.Jump > 0 {
+		pc.jump(in.Jump)
+	}
+
+	if pc.YVelo < Gravity {
+		// screen movement vs player movement
+		if (pc.Y < 160 && pc.View.Y-pc.YVelo < 0 && pc.YVelo < 0) ||
+			(pc.Y > 160 && pc.View.Y-pc.YVelo > -120 && pc.YVelo > 0) {
+			pc.View.Y -= pc.YVelo
+			l.scroll(0, -pc.YVelo)
+		} else {
+			pc.Y += pc.YVelo
+		}
+
+		pc.YVelo++
+
+		if pc.YVelo >= 0 {
+			base := (pc.Y - pc.View.Y + PlayerHeight + 1) / TileSize // checks immediately BELOW base of sprite
+			left := (pc.X - pc.View.X) / TileSize
+			right := (pc.X - pc.View.X + PlayerWidth) / TileSize
+			if l.solid(left, base) || l.solid(right, base) {
+				pc.Y = (base * TileSize) - TileSize + pc.View.Y
+				pc.YVelo = Gravity
+			}
+		}
+	}
+	base := (pc.Y - pc.View.Y + PlayerHeight + 1) / TileSize // checks immediately BELOW base of sprite
+	left := (pc.X - pc.View.X) / TileSize
+	right := (pc.X - pc.View.X + PlayerWidth) / TileSize
+	// gravity fixer
+	if pc.Status != "ground" && !l.solid(left, base) && !l.solid(right, base) {
+		switch {
+		case pc.View.Y > -120 && pc.Y > 160:
+			pc.View.Y -= 3
+			l.scroll(0, -3)
+		default:
+			pc.Y += 3
+		}
+	}
+
+	freshBase := (pc.Y - pc.View.Y + PlayerHeight + 1) / TileSize // checks immediately BELOW base of sprite
+	if l.solid(left, freshBase) || l.solid(right, freshBase) {
+		pc.Status = "ground"
+	} else if pc.YVelo == Gravity {
+		pc.Status = "fall"
+	}
+
+	for _, c := range l.Creatures {
+		c.move(l.rng)
+	}
+
+	playerBox := image.Rect(pc.X, pc.Y, pc.X+PlayerWidth, pc.Y+PlayerWidth)
+
+	remaining := l.Treasures[:0]
+	for _, t := range l.Treasures {
+		if !playerBox.Overlaps(image.Rect(t.X, t.Y, t.X+TileSize, t.Y+TileSize)) {
+			remaining = append(remaining, t)
+			continue
+		}
+		kind := TreasureKinds[t.ID]
+		result.Points += kind.Value
+		if kind.Gem {
+			l.Gem = true
+		}
+	}
+	l.Treasures = remaining
+
+	for _, h := range l.Hazards {
+		if playerBox.Overlaps(image.Rect(h.X, h.Y, h.X+TileSize, h.Y+TileSize)) {
+			pc.Death()
+			result.Died = true
+			return result
+		}
+	}
+	for _, c := range l.Creatures {
+		if playerBox.Overlaps(image.Rect(c.X, c.Y, c.X+TileSize, c.Y+TileSize)) {
+			pc.Death()
+			result.Died = true
+			return result
+		}
+	}
+
+	if l.Gem && playerBox.Overlaps(l.exitBox()) {
+		l.Gem = false
+		result.Complete = true
+	}
+	return result
+}
+
+// exitBox is the portal's area on screen
+func (l *Level) exitBox() image.Rectangle {
+	x, y := l.Spec.ExitX+l.Player.View.X, l.Spec.ExitY+l.Player.View.Y
+	return image.Rect(x, y, x+PortalWidth, y+PortalHeight)
+}
+
+// LayoutCopy copies a level layout, so changes to one don't show up in the other
+func LayoutCopy(layout [][]int) (fresh [][]int) {
+	fresh = make([][]int, len(layout))
+	for i := range layout {
+		fresh[i] = append([]int{}, layout[i]...)
+	}
+	return
+}
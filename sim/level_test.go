@@ -0,0 +1,203 @@
+package sim
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+// levelHeight is the height of every level background so far
+const levelHeight = 600
+
+// loadSpecs reads the game's level files
+func loadSpecs(t *testing.T) []*LevelSpec {
+	var specs []*LevelSpec
+	for _, file := range []string{"../levels.json", "../levels-vorp-minor.json"} {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("reading %s: %v", file, err)
+		}
+		var levels []*LevelSpec
+		if err := json.Unmarshal(content, &levels); err != nil {
+			t.Fatalf("parsing %s: %v", file, err)
+		}
+		specs = append(specs, levels...)
+	}
+	for _, s := range specs {
+		s.Height = levelHeight
+	}
+	return specs
+}
+
+// flatSpec is a level with a floor along the bottom row and nothing else, for placing things in by hand
+func flatSpec() *LevelSpec {
+	rows := levelHeight / TileSize
+	layout := make([][]int, 4)
+	for i := range layout {
+		layout[i] = make([]int, TileXCount*rows)
+	}
+	for i := TileXCount * (rows - 1); i < TileXCount*rows; i++ {
+		layout[0][i] = 1
+	}
+	return &LevelSpec{Name: "Flat", PlayerX: 20, PlayerY: 380, ExitX: 625, ExitY: 400, Height: levelHeight, Layout: layout}
+}
+
+func TestTickThousandsOfRandomInputs(t *testing.T) {
+	rng := rand.New(rand.NewSource(46))
+	for _, spec := range loadSpecs(t) {
+		l := NewLevel(spec, NewCharacter("Mona", 100), 1)
+		deaths, completions := 0, 0
+		jump := 0
+		for tick := 0; tick < 5000; tick++ {
+			in := Input{Left: rng.Intn(3) == 0, Right: rng.Intn(2) == 0}
+			if rng.Intn(4) == 0 {
+				jump++
+			} else {
+				jump = 0
+			}
+			in.Jump = jump
+
+			result := l.Tick(in)
+			if x := l.Player.X - l.Player.View.X; x < 0 || x > TileXCount*TileSize-PlayerWidth {
+				t.Fatalf("%s tick %d: player left the level at x %d", spec.Name, tick, x)
+			}
+			switch {
+			case result.Died:
+				deaths++
+				l = NewLevel(spec, NewCharacter("Mona", 100), 1)
+			case result.Complete:
+				completions++
+				l = NewLevel(spec, NewCharacter("Mona", 100), 1)
+			}
+		}
+		t.Logf("%s: %d deaths, %d completions", spec.Name, deaths, completions)
+	}
+}
+
+// jumpOnce holds Jump until the player lands again, and gives the highest point reached
+func jumpOnce(t *testing.T, l *Level) int {
+	l.Tick(Input{Jump: 1})
+	if l.Player.Status != "jump" {
+		t.Fatalf("status after jump = %s, want jump", l.Player.Status)
+	}
+	highest := l.Player.Y
+	for hold := 2; hold < 200 && l.Player.Status != "ground"; hold++ {
+		l.Tick(Input{Jump: hold})
+		if l.Player.Y < highest {
+			highest = l.Player.Y
+		}
+	}
+	if l.Player.Status != "ground" {
+		t.Fatalf("still %s after a long jump", l.Player.Status)
+	}
+	return highest
+}
+
+func TestJumpLandsWhereItStarted(t *testing.T) {
+	l := NewLevel(flatSpec(), NewCharacter("Mona", 100), 1)
+	jumpOnce(t, l) // levels start the player a little above the floor
+	groundY := l.Player.Y
+
+	highest := jumpOnce(t, l)
+	if l.Player.Y != groundY {
+		t.Errorf("landed at y %d, want %d", l.Player.Y, groundY)
+	}
+	if highest >= groundY {
+		t.Errorf("jump never rose above y %d", groundY)
+	}
+}
+
+func TestCollectGemAndLeave(t *testing.T) {
+	spec := flatSpec()
+	rows := levelHeight / TileSize
+	spec.Layout[3][TileXCount*(rows-2)+3] = 4 // ball on the floor
+	spec.Layout[3][TileXCount*(rows-2)+6] = 3 // gem on the floor
+	l := NewLevel(spec, NewCharacter("Mona", 100), 1)
+
+	points := 0
+	for tick := 0; tick < 1000; tick++ {
+		result := l.Tick(Input{Right: true})
+		points += result.Points
+		if result.Died {
+			t.Fatalf("died walking an empty floor at tick %d", tick)
+		}
+		if result.Complete {
+			if points != TreasureKinds[4].Value {
+				t.Errorf("points = %d, want %d for the ball", points, TreasureKinds[4].Value)
+			}
+			if len(l.Treasures) != 0 {
+				t.Errorf("%d treasures left, want 0", len(l.Treasures))
+			}
+			return
+		}
+	}
+	t.Fatalf("never reached the portal: x %d, view %d, gem %v", l.Player.X, l.Player.View.X, l.Gem)
+}
+
+func TestHazardKills(t *testing.T) {
+	spec := flatSpec()
+	rows := levelHeight / TileSize
+	spec.Layout[1][TileXCount*(rows-2)+4] = 5
+	pc := NewCharacter("Mona", 100)
+	l := NewLevel(spec, pc, 1)
+
+	for tick := 0; tick < 500; tick++ {
+		if result := l.Tick(Input{Right: true}); result.Died {
+			if pc.Status != "dying" || pc.Lives != 3 || pc.HP != 0 {
+				t.Errorf("after death: status %s, lives %d, hp %d; want dying, 3, 0", pc.Status, pc.Lives, pc.HP)
+			}
+			return
+		}
+	}
+	t.Fatal("walked through the hazard")
+}
+
+func TestLevelsIndependent(t *testing.T) {
+	spec := flatSpec()
+	rows := levelHeight / TileSize
+	spec.Layout[1][TileXCount*(rows-2)+5] = 5
+	spec.Layout[2][TileXCount*(rows-2)+9] = 6
+	spec.Layout[3][TileXCount*(rows-3)+3] = 4
+
+	a := NewLevel(spec, NewCharacter("Mona", 100), 1)
+	b := NewLevel(spec, NewCharacter("Lisa", 100), 1)
+	if len(a.Hazards) != 1 || len(a.Creatures) != 1 || len(a.Treasures) != 1 || len(a.Bricks) != TileXCount {
+		t.Fatalf("populated %d hazards, %d creatures, %d treasures, %d bricks; want 1, 1, 1, %d",
+			len(a.Hazards), len(a.Creatures), len(a.Treasures), len(a.Bricks), TileXCount)
+	}
+	hazardX := b.Hazards[0].X
+
+	a.Tiles[0][0] = 1
+	for i := 0; i < 100; i++ {
+		a.Tick(Input{Right: true})
+	}
+
+	if spec.Layout[0][0] != 0 || b.Tiles[0][0] != 0 {
+		t.Error("changing one level's tiles changed the layout or the other level")
+	}
+	if b.Hazards[0].X != hazardX || b.Player.X != spec.PlayerX || b.Ticks != 0 {
+		t.Errorf("other level moved: hazard x %d, player x %d, ticks %d", b.Hazards[0].X, b.Player.X, b.Ticks)
+	}
+}
+
+func TestFastForwardPicksUpRandomness(t *testing.T) {
+	spec := flatSpec()
+	rows := levelHeight / TileSize
+	spec.Layout[2][TileXCount*(rows-2)+12] = 6
+	a := NewLevel(spec, NewCharacter("Mona", 100), 50)
+	for i := 0; i < 500; i++ {
+		a.Tick(Input{})
+	}
+	if a.Draws() == 0 {
+		t.Fatal("creature never drew a random number")
+	}
+
+	b := RestoreLevel(spec, NewCharacter("Mona", 100), spec.Layout, 50)
+	b.FastForward(a.Draws())
+	for i := 0; i < 20; i++ {
+		if x, y := a.rng.Int63(), b.rng.Int63(); x != y {
+			t.Fatalf("draw %d after fast forward = %d, want %d", i, y, x)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package sim
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	replayMagic  = "SSRP"
+	replayFormat = 1
+
+	inputLeft      = 1 << 0
+	inputRight     = 1 << 1
+	inputJump      = 1 << 2 // Jump held
+	inputJumpStart = 1 << 3 // Jump pressed this tick
+)
+
+// Replay is the input for every tick of one attempt at a level, with what is needed to start the level the same way again
+type Replay struct {
+	Version string // game version that recorded it
+	Level   string
+	Seed    int64
+	Inputs  []Input
+}
+
+// NewReplay starts recording an attempt at l
+func NewReplay(version string, l *Level) *Replay {
+	return &Replay{Version: version, Level: l.Spec.Name, Seed: l.Seed}
+}
+
+// Record adds one tick's input
+func (r *Replay) Record(in Input) {
+	r.Inputs = append(r.Inputs, in)
+}
+
+// inputFlags packs an input into a byte. Only whether Jump was just pressed or is held changes the simulation, so longer durations aren't kept.
+func inputFlags(in Input) byte {
+	var f byte
+	if in.Left {
+		f |= inputLeft
+	}
+	if in.Right {
+		f |= inputRight
+	}
+	if in.Jump > 0 {
+		f |= inputJump
+	}
+	if in.Jump == 1 {
+		f |= inputJumpStart
+	}
+	return f
+}
+
+// WriteTo writes the replay in its compact form: a header, then runs of identical inputs as a flags byte and a count
+func (r *Replay) WriteTo(w io.Writer) (int64, error) {
+	var buf []byte
+	buf = append(buf, replayMagic...)
+	buf = append(buf, replayFormat)
+	buf = appendString(buf, r.Version)
+	buf = appendString(buf, r.Level)
+	buf = binary.AppendVarint(buf, r.Seed)
+	buf = binary.AppendUvarint(buf, uint64(len(r.Inputs)))
+	for i := 0; i < len(r.Inputs); {
+		f := inputFlags(r.Inputs[i])
+		run := 1
+		for i+run < len(r.Inputs) && inputFlags(r.Inputs[i+run]) == f {
+			run++
+		}
+		buf = append(buf, f)
+		buf = binary.AppendUvarint(buf, uint64(run))
+		i += run
+	}
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// ReadReplay reads a replay written by WriteTo
+func ReadReplay(r io.Reader) (*Replay, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(replayMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("reading replay header: %w", err)
+	}
+	if string(header[:len(replayMagic)]) != replayMagic {
+		return nil, errors.New("not a replay file")
+	}
+	if header[len(replayMagic)] != replayFormat {
+		return nil, fmt.Errorf("unknown replay format %d", header[len(replayMagic)])
+	}
+
+	replay := &Replay{}
+	var err error
+	if replay.Version, err = readString(br); err != nil {
+		return nil, fmt.Errorf("reading replay version: %w", err)
+	}
+	if replay.Level, err = readString(br); err != nil {
+		return nil, fmt.Errorf("reading replay level: %w", err)
+	}
+	if replay.Seed, err = binary.ReadVarint(br); err != nil {
+		return nil, fmt.Errorf("reading replay seed: %w", err)
+	}
+	ticks, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("reading replay length: %w", err)
+	}
+
+	jump := 0
+	for uint64(len(replay.Inputs)) < ticks {
+		f, err := br.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("reading replay at tick %d: %w", len(replay.Inputs), err)
+		}
+		run, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("reading replay at tick %d: %w", len(replay.Inputs), err)
+		}
+		if run == 0 || uint64(len(replay.Inputs))+run > ticks {
+			return nil, fmt.Errorf("bad run of %d ticks at tick %d", run, len(replay.Inputs))
+		}
+		for ; run > 0; run-- {
+			switch {
+			case f&inputJumpStart != 0:
+				jump = 1
+			case f&inputJump != 0 && jump == 0:
+				jump = 2 // held since before the level started
+			case f&inputJump != 0:
+				jump++
+			default:
+				jump = 0
+			}
+			replay.Inputs = append(replay.Inputs, Input{Left: f&inputLeft != 0, Right: f&inputRight != 0, Jump: jump})
+		}
+	}
+	return replay, nil
+}
+
+func readString(br *bufio.Reader) (string, error) {
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return "", err
+	}
+	if n > 1024 {
+		return "", fmt.Errorf("string of %d bytes is too long", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(br, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,81 @@
+package sim
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// randomRun plays up to ticks ticks of random input on l, recording them, and stops when the attempt ends
+func randomRun(l *Level, rng *rand.Rand, ticks int, replay *Replay) {
+	jump := 0
+	for tick := 0; tick < ticks; tick++ {
+		in := Input{Left: rng.Intn(3) == 0, Right: rng.Intn(2) == 0}
+		if rng.Intn(4) == 0 {
+			jump++
+		} else {
+			jump = 0
+		}
+		in.Jump = jump
+		replay.Record(in)
+		if result := l.Tick(in); result.Died || result.Complete {
+			return
+		}
+	}
+}
+
+func TestReplayReproducesRun(t *testing.T) {
+	rng := rand.New(rand.NewSource(49))
+	for _, spec := range loadSpecs(t) {
+		recorded := NewLevel(spec, NewCharacter("Mona", 100), rng.Int63())
+		replay := NewReplay("test", recorded)
+		randomRun(recorded, rng, 3000, replay)
+
+		var buf bytes.Buffer
+		if _, err := replay.WriteTo(&buf); err != nil {
+			t.Fatalf("%s: writing replay: %v", spec.Name, err)
+		}
+		size := buf.Len()
+		loaded, err := ReadReplay(&buf)
+		if err != nil {
+			t.Fatalf("%s: reading replay: %v", spec.Name, err)
+		}
+		if loaded.Version != "test" || loaded.Level != spec.Name || loaded.Seed != recorded.Seed || len(loaded.Inputs) != len(replay.Inputs) {
+			t.Fatalf("%s: replay header %q %q %d with %d ticks, want %q %q %d with %d",
+				spec.Name, loaded.Version, loaded.Level, loaded.Seed, len(loaded.Inputs), "test", spec.Name, recorded.Seed, len(replay.Inputs))
+		}
+
+		played := NewLevel(spec, NewCharacter("Mona", 100), loaded.Seed)
+		for _, in := range loaded.Inputs {
+			played.Tick(in)
+		}
+		if !reflect.DeepEqual(played.Player, recorded.Player) {
+			t.Errorf("%s: replayed player %+v, recorded %+v", spec.Name, *played.Player, *recorded.Player)
+		}
+		if !reflect.DeepEqual(played.Creatures, recorded.Creatures) || !reflect.DeepEqual(played.Treasures, recorded.Treasures) || played.Ticks != recorded.Ticks {
+			t.Errorf("%s: replayed level differs from the recorded one after %d ticks", spec.Name, recorded.Ticks)
+		}
+		t.Logf("%s: %d ticks in %d bytes", spec.Name, len(replay.Inputs), size)
+	}
+}
+
+func TestReadReplayRejectsDamage(t *testing.T) {
+	replay := &Replay{Version: "test", Level: "Flat", Seed: -7, Inputs: []Input{{Right: true}, {Right: true, Jump: 1}, {Jump: 2}}}
+	var buf bytes.Buffer
+	replay.WriteTo(&buf)
+	data := buf.Bytes()
+
+	for _, damaged := range [][]byte{data[:len(data)-1], append([]byte("XXXX"), data[4:]...), data[:3]} {
+		if _, err := ReadReplay(bytes.NewReader(damaged)); err == nil {
+			t.Errorf("reading damaged replay %q: err = nil", damaged)
+		}
+	}
+	loaded, err := ReadReplay(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("reading replay: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, replay) {
+		t.Errorf("loaded %+v, want %+v", loaded, replay)
+	}
+}
@@ -0,0 +1,35 @@
+// Package sim runs level gameplay one tick at a time, without a window, so it can be driven by the game, tests or tools
+package sim
+
+const (
+	// ScreenHeight is the height of the visible part of a level
+	ScreenHeight = 480
+
+	// TileSize is the width and height of a layout tile
+	TileSize = 50
+	// TileXCount is how many tiles wide a layout is
+	TileXCount = 16
+
+	PlayerWidth  = 48
+	PlayerHeight = 48
+
+	PortalWidth  = 100
+	PortalHeight = 150
+
+	// Gravity is the fall speed, and the launch speed of a jump
+	Gravity = 20
+)
+
+// Input is the state of the controls for one tick
+type Input struct {
+	Left  bool
+	Right bool
+	Jump  int // ticks Jump has been held, 0 while it is up
+}
+
+// Result reports what happened during a tick
+type Result struct {
+	Points   int  // score for treasures collected
+	Died     bool // the player touched a hazard or creature
+	Complete bool // the player went through the portal with the gem
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/mroobit/untitled-sidescroller/sim"
+)
+
+// LevelSnapshot captures the full state of a level in progress, so it can be suspended and resumed exactly
+type LevelSnapshot struct {
+	Level     string
+	Gem       bool
+	Player    PlayerSnapshot
+	ViewX     int
+	ViewY     int
+	LevelMap  [][]int
+	Creatures []CreatureSnapshot
+	Hazards   []HazardSnapshot
+	Treasures []TreasureSnapshot
+	Draws     int64 `json:",omitempty"` // how far the level's randomness had got, so creatures carry on as they would have
+}
+
+// PlayerSnapshot holds the player character's in-level state
+type PlayerSnapshot struct {
+	X      int
+	Y      int
+	YVelo  int
+	Facing int
+	HP     int
+	Status string
+}
+
+// CreatureSnapshot holds a creature's location, health, damage and movement counters
+type CreatureSnapshot struct {
+	Name        string
+	X           int
+	Y           int
+	Facing      int
+	HP          int
+	HPTotal     int `json:",omitempty"` // missing from saves before it was kept; HP then
+	Damage      int `json:",omitempty"` // missing from saves before it was kept; 100 then, like every creature
+	SeesChar    bool
+	MovementCtr int
+	PauseCtr    int
+}
+
+// HazardSnapshot holds a hazard's location
+type HazardSnapshot struct {
+	Name   string
+	X      int
+	Y      int
+	Damage int
+}
+
+// TreasureSnapshot holds an uncollected treasure's type and location
+type TreasureSnapshot struct {
+	ID int
+	X  int
+	Y  int
+}
+
+// NewLevelSnapshot captures a level's state
+func NewLevelSnapshot(l *sim.Level) *LevelSnapshot {
+	log.Printf("Capturing snapshot of %s", l.Spec.Name)
+	pc := l.Player
+	snap := &LevelSnapshot{
+		Level: l.Spec.Name,
+		Gem:   l.Gem,
+		Player: PlayerSnapshot{
+			X:      pc.X,
+			Y:      pc.Y,
+			YVelo:  pc.YVelo,
+			Facing: pc.Facing,
+			HP:     pc.HP,
+			Status: pc.Status,
+		},
+		ViewX:    pc.View.X,
+		ViewY:    pc.View.Y,
+		LevelMap: sim.LayoutCopy(l.Tiles),
+		Draws:    l.Draws(),
+	}
+	for _, c := range l.Creatures {
+		snap.Creatures = append(snap.Creatures, CreatureSnapshot{c.Name, c.X, c.Y, c.Facing, c.HP, c.HPTotal, c.Damage, c.SeesChar, c.MovementCtr, c.PauseCtr})
+	}
+	for _, h := range l.Hazards {
+		snap.Hazards = append(snap.Hazards, HazardSnapshot{h.Name, h.X, h.Y, h.Damage})
+	}
+	for _, t := range l.Treasures {
+		snap.Treasures = append(snap.Treasures, TreasureSnapshot{t.ID, t.X, t.Y})
+	}
+	return snap
+}
+
+// NewPlayFromSnapshot restores a suspended level, putting player back where they were, and creates the Play to resume it with randomness drawn from seed where the snapshot left off
+func NewPlayFromSnapshot(snap *LevelSnapshot, levels []*LevelData, player *sim.Character, seed int64) (*Play, error) {
+	var data *LevelData
+	for _, l := range levels {
+		if l.Name == snap.Level {
+			data = l
+		}
+	}
+	if data == nil {
+		return nil, fmt.Errorf("suspended level %q not found", snap.Level)
+	}
+	log.Printf("Restoring snapshot of %s", data.Name)
+
+	player.View = sim.View{X: snap.ViewX, Y: snap.ViewY}
+	player.X = snap.Player.X
+	player.Y = snap.Player.Y
+	player.YVelo = snap.Player.YVelo
+	player.Facing = snap.Player.Facing
+	player.HP = snap.Player.HP
+	player.Status = snap.Player.Status
+
+	l := sim.RestoreLevel(data.spec(), player, snap.LevelMap, seed)
+	l.FastForward(snap.Draws)
+	l.Gem = snap.Gem
+	for _, c := range snap.Creatures {
+		hpTotal, damage := c.HPTotal, c.Damage
+		if hpTotal == 0 {
+			hpTotal = c.HP
+		}
+		if damage == 0 {
+			damage = 100
+		}
+		nc := sim.NewCreature(c.Name, c.X, c.Y, hpTotal, damage)
+		nc.HP = c.HP
+		nc.Facing = c.Facing
+		nc.SeesChar = c.SeesChar
+		nc.MovementCtr = c.MovementCtr
+		nc.PauseCtr = c.PauseCtr
+		l.Creatures = append(l.Creatures, nc)
+	}
+	for _, h := range snap.Hazards {
+		l.Hazards = append(l.Hazards, sim.NewHazard(h.Name, h.X, h.Y, h.Damage))
+	}
+	for _, t := range snap.Treasures {
+		if _, ok := sim.TreasureKinds[t.ID]; !ok {
+			return nil, fmt.Errorf("unknown treasure type %d in suspended level", t.ID)
+		}
+		l.Treasures = append(l.Treasures, &sim.Treasure{ID: t.ID, X: t.X, Y: t.Y})
+	}
+	play := NewPlay(data, l)
+	play.recording = nil // a replay has to start from the beginning of the level
+	return play, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mroobit/untitled-sidescroller/sim"
+)
+
+func TestLevelSnapshotRoundTrip(t *testing.T) {
+	layout := [][]int{{0, 0, 1, 1}, {0, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}}
+	lvl := &LevelData{Name: "Test Level", Layout: layout}
+
+	pc := sim.NewCharacter("Mona", 100)
+	pc.X, pc.Y = 140, 260
+	pc.View = sim.View{X: -35, Y: -120}
+	pc.YVelo = -4
+	pc.Status = "jump"
+	pc.HP = 80
+
+	level := sim.RestoreLevel(lvl.spec(), pc, layout, 1)
+	nc := sim.NewCreature("teen yorp", 355, 380, 120, 60)
+	nc.HP = 45
+	nc.MovementCtr = 12
+	nc.PauseCtr = 31
+	level.Creatures = append(level.Creatures, nc)
+	level.Hazards = append(level.Hazards, sim.NewHazard("blob", 205, 380, 100))
+	level.Treasures = append(level.Treasures, &sim.Treasure{ID: 4, X: 405, Y: 130}, &sim.Treasure{ID: 3, X: 505, Y: 80})
+	level.Gem = true
+	level.FastForward(7)
+
+	before := NewLevelSnapshot(level)
+	data, err := json.Marshal(before)
+	if err != nil {
+		t.Fatalf("marshalling snapshot: %v", err)
+	}
+
+	var decoded *LevelSnapshot
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshalling snapshot: %v", err)
+	}
+	restored, err := NewPlayFromSnapshot(decoded, []*LevelData{lvl}, sim.NewCharacter("Mona", 100), 1)
+	if err != nil {
+		t.Fatalf("restoring snapshot: %v", err)
+	}
+	if restored.data != lvl || !restored.level.Gem {
+		t.Errorf("restored Play = %+v, want Test Level with gem", restored)
+	}
+	if len(restored.level.Bricks) != 2 {
+		t.Errorf("restored %d bricks, want 2", len(restored.level.Bricks))
+	}
+	if c := restored.level.Creatures[0]; c.HP != 45 || c.HPTotal != 120 || c.Damage != 60 {
+		t.Errorf("restored creature hp %d/%d, damage %d; want 45/120, 60", c.HP, c.HPTotal, c.Damage)
+	}
+	if restored.level.Draws() != 7 {
+		t.Errorf("restored randomness at draw %d, want 7", restored.level.Draws())
+	}
+	if restored.recording != nil {
+		t.Error("resumed level is recording a replay that can't start from the beginning")
+	}
+
+	after := NewLevelSnapshot(restored.level)
+	if !reflect.DeepEqual(before, after) {
+		t.Errorf("snapshot changed across round trip:\nbefore %+v\nafter  %+v", before, after)
+	}
+}
+
+func TestNewPlayFromSnapshotUnknownLevel(t *testing.T) {
+	_, err := NewPlayFromSnapshot(&LevelSnapshot{Level: "Nowhere"}, nil, sim.NewCharacter("Mona", 100), 1)
+	if err == nil {
+		t.Fatal("err = nil, want unknown level error")
+	}
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"embed"
-	"encoding/json"
 	"fmt"
 	"image"
 	"log"
@@ -11,12 +10,14 @@ import (
 	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/mroobit/untitled-sidescroller/sim"
 	"github.com/tinne26/etxt"
 )
 
 // Load is a Game State for loading the game
 type Load struct {
+	stateHooks
 	splash []*ebiten.Image
 	curr   int
 }
@@ -26,20 +27,19 @@ func (l *Load) Update(g *Game) error {
 	if loaded == false {
 		loadFonts()
 		g.txtRenderer = newRenderer()
-		//loadMenuItems = findSaveFiles()
-		initializeMenus()
+		g.levelTotal = countLevels(FileSystem)
+		initializeMenus(g)
 		initializeTreasures()
-
-		t := NewTitle()
-		g.state["Title"] = t
 		loaded = true
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyF) { // developer skip-ahead
-		g.mode = "Title"
-	}
-	if g.count > 200 {
-		g.mode = "Title"
+	if actionJustPressed(actionConfirm) || actionJustPressed(actionBack) || g.count > 200 {
 		log.Printf("Changing state to Title")
+		g.replace(NewTitle())
+		if pendingReplay != nil {
+			replay := pendingReplay
+			pendingReplay = nil
+			return startReplay(g, replay)
+		}
 	}
 	return nil
 }
@@ -50,249 +50,512 @@ func (l *Load) Draw(screen *ebiten.Image, g *Game) {
 	screen.DrawImage(l.splash[l.curr], op)
 }
 
-// Title is a Game State containing Title Screen and a Menu
+// Title is a Game State containing Title Screen and a stack of nested Menus
 type Title struct {
-	header string
-	menu   *Menu
+	stateHooks
+	menus *MenuStack
 }
 
 // NewTitle creates a new *Title with default main menu
 func NewTitle() *Title {
 	title := &Title{
-		header: mainHeader,
-		menu:   mainMenu,
+		menus: NewMenuStack(mainMenu),
 	}
 	return title
 }
 
-// Load loads a new Menu into *Title
+// Load opens a Menu on top of the current one
 func (t *Title) Load(m *Menu) {
-	t.menu = m
+	t.menus.Push(m)
+}
+
+func (t *Title) menuStack() *MenuStack {
+	return t.menus
 }
 
-// Update changes active selection and selects a MenuItem based on user input
+// Update changes active selection and runs the selected MenuItem's action based on user input
 func (t *Title) Update(g *Game) error {
-	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
-		selection := t.menu.Select()
+	menu := t.menus.Top()
+	if actionJustPressed(actionConfirm) {
+		return menu.Select(g)
+	}
+	if actionJustPressed(actionBack) || backPressed() {
+		t.menus.Pop()
+		return nil
+	}
+	if actionRepeating(actionMoveDown) {
+		menu.Next()
+	}
+	if actionRepeating(actionMoveUp) {
+		menu.Prev()
+	}
+	if actionJustPressed(actionPageDown) {
+		menu.PageDown()
+	}
+	if actionJustPressed(actionPageUp) {
+		menu.PageUp()
+	}
+	if actionJustPressed(actionMoveRight) {
+		menu.Adjust(g, 1)
+	}
+	if actionJustPressed(actionMoveLeft) {
+		menu.Adjust(g, -1)
+	}
+	return menu.UpdatePointer(g)
+}
 
-		switch {
-		case selection == "New Game":
-			log.Printf("Starting New Game")
-			// prompt for character name
-			// create character with provided name
-			playerView = NewViewer()
-			worldPlayerView = NewViewer()
-
-			playerChar = NewCharacter("Mona", spriteSheet, playerView, 100)
-			worldPlayer = NewWorldChar(spriteSheet, worldPlayerView)
-
-			g.score = 0
-			g.count = 0
-
-			//	loadLevels()
-			world := NewWorld()
-			world.Load(FileSystem)
-			g.state["World"] = world
-			g.mode = "World"
-
-			// saveData := NewSaveData()
-			// saveData.Initialize("Mona")
-		case selection == "Load Game":
-			log.Printf("Choose a Saved Game")
-			if len(loadMenuItems) > 1 {
-				t := NewTitle()
-				t.Load(loadMenu)
-				t.header = loadHeader
-				g.state["Title"] = t
-			}
-			g.mode = "Title"
-		case selection == "How To Play":
-			//TODO
-			log.Printf("Display Instructions -- not yet implemented")
-			g.mode = "Title"
-		case selection == "Acknowledgements":
-			c := NewInfo()
-			c.message = infoCredit
-			c.previous = "Title"
-			g.state["Info"] = c
-			g.mode = "Info"
-		case selection == "Exit":
-			log.Printf("Attempting to Exit Game")
-			return ErrExit
-		case strings.HasSuffix(selection, ".json"):
-			gameData := LoadGame(selection)
-
-			playerView = NewViewer()
-			worldPlayerView = NewViewer()
-			worldPlayerView.xCoord = gameData.WorldViewX
-			worldPlayerView.yCoord = gameData.WorldViewY
-
-			playerChar = NewCharacter(gameData.Name, spriteSheet, playerView, 100)
-			playerChar.lives = gameData.Lives
-
-			worldPlayer = NewWorldChar(spriteSheet, worldPlayerView)
-			worldPlayer.xCoord = gameData.WorldCharX
-			worldPlayer.yCoord = gameData.WorldCharY
-
-			g.score = gameData.Score
-			g.count = gameData.Count
-			world := NewWorld()
-			world.Load(FileSystem)
-			for _, level := range world.levels {
-				if gameData.Complete[level.Name] {
-					level.Complete = true
-				}
-			}
+// startNewGame asks for the character's name, then starts the game
+func startNewGame(g *Game) error {
+	g.push(NewTextInput("Name your character", "Mona", newGame))
+	return nil
+}
+
+// newGame creates a new character with the given name and a new World, then moves to the World
+func newGame(g *Game, name string) error {
+	log.Printf("Starting New Game")
+	g.session = NewSession(name)
 
-			g.state["World"] = world
-			g.mode = "World"
+	world := NewWorld()
+	g.transitionTo(transitionToWorld, func() { g.reset(world) })
+	return nil
+}
 
-			/*
-				world.Load()
-			*/
+// showMainMenu returns to a fresh Title at the main menu
+func showMainMenu(g *Game) error {
+	g.transitionTo(transitionToTitle, func() { g.reset(NewTitle()) })
+	return nil
+}
 
-		case selection == "Main Menu":
-			t := NewTitle()
-			t.Load(mainMenu)
-			t.header = mainHeader
-			g.state["Title"] = t
-			g.mode = "Title"
-		}
+// showLoadMenu refreshes the Load Game menu and opens it
+func showLoadMenu(g *Game) error {
+	log.Printf("Choose a Saved Game")
+	refreshLoadMenu(g)
+	if hasSaves() {
+		pushMenu(g, loadMenu)
+	}
+	return nil
+}
+
+// showSlotMenu opens the Load, Copy and Delete actions for a save slot
+func showSlotMenu(g *Game, slot int) {
+	pushMenu(g, newSlotMenu(slot, g.levelTotal))
+}
+
+// showSettings opens the Settings menu
+func showSettings(g *Game) error {
+	pushMenu(g, newSettingsMenu())
+	return nil
+}
+
+// showHighScores opens the best scores across saved games
+func showHighScores(g *Game) error {
+	refreshLoadMenu(g)
+	pushMenu(g, newPage("High Scores", highScores()))
+	return nil
+}
+
+// showAcknowledgements opens the credits
+func showAcknowledgements(g *Game) error {
+	pushMenu(g, newPage("Acknowledgements", infoCredit))
+	return nil
+}
+
+// exitGame ends the game loop
+func exitGame(g *Game) error {
+	log.Printf("Attempting to Exit Game")
+	return ErrExit
+}
+
+// loadSlot starts play from the game saved in a slot, resuming a suspended level if there is one
+func loadSlot(g *Game, slot int) {
+	gameData, err := LoadGame(slot)
+	if err != nil {
+		g.setNotice("Could not load save: " + err.Error())
+		return
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) {
-		t.menu.Next()
+
+	g.session = NewSessionFromSave(gameData)
+	world := NewWorld()
+	world.enter(world.planetNamed(gameData.World))
+	for _, level := range world.allLevels() {
+		if gameData.Complete[level.Name] {
+			level.Complete = true
+		}
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) {
-		t.menu.Prev()
+
+	var play *Play
+	if gameData.Suspended != nil {
+		play, err = NewPlayFromSnapshot(gameData.Suspended, world.allLevels(), g.session.player, g.session.levelSeed(gameData.Suspended.Level))
+		if err != nil {
+			g.setNotice("Could not resume level: " + err.Error())
+		}
 	}
-	return nil
+
+	g.transitionTo(transitionToWorld, func() {
+		g.reset(world)
+		if play != nil {
+			g.push(play)
+			g.push(NewPause("message", "Resuming "+play.data.Name))
+		}
+	})
 }
 
-// Draw displays Title Screen and Menu, highlighting active selection
+// Draw displays the current menu on the Title Screen
 func (t *Title) Draw(screen *ebiten.Image, g *Game) {
+	drawMenuPage(screen, g, t.menus.Top())
+}
+
+// drawMenuPage draws a menu's header, body and options down the screen, highlighting the active selection and greying out disabled options
+func drawMenuPage(screen *ebiten.Image, g *Game, m *Menu) {
 	textColor = menuColorActive
 	g.txtRenderer.SetAlign(etxt.YCenter, etxt.XCenter) // make sure type is centered (gets changed in Play/Pause)
 	g.txtRenderer.SetTarget(screen)
 
 	locY := 80
 	g.txtRenderer.SetColor(menuColorInactive)
-	g.txtRenderer.Draw(t.header, 300, locY)
+	g.txtRenderer.Draw(m.header, winWidth/2, locY)
 
-	var menuHead = t.menu.head
-	locY = 150
-	for i := t.menu.length; i > 0; i-- {
+	locY = 130
+	g.txtRenderer.SetSizePx(18)
+	for _, b := range m.body {
+		lines := strings.Count(b, "\n") + 1
+		g.txtRenderer.Draw(b, winWidth/2, locY+(lines-1)*12)
+		locY += lines*24 + 12
+	}
+	g.txtRenderer.SetSizePx(32)
+
+	menuHead := m.head
+	if locY < 150 {
+		locY = 150
+	} else {
+		locY += 20
+	}
+	step := 50
+	switch {
+	case m.details:
+		step = 60
+	case m.length > 6:
+		step = 40
+	}
+	m.setVisible((winHeight-80-locY)/step + 1)
+	for i := m.length; i > 0; i-- {
+		menuHead.rect = image.Rectangle{} // hidden items can't be clicked
+		menuHead = menuHead.next
+	}
+	menuHead, count, above, below := m.window()
+	if above {
+		drawArrow(screen, winWidth/2, locY-step/2-4, true, menuColorInactive)
+	}
+	for i := count; i > 0; i-- {
 		textColor = menuColorInactive
-		if menuHead == t.menu.active {
+		if menuHead == m.active {
 			textColor = menuColorActive
 		}
-		if menuHead == t.menu.active &&
-			((t.menu.active.option == "Load Game" && len(loadMenuItems) < 2) || t.menu.active.option == "How To Play") {
+		if !menuHead.Enabled() {
 			textColor = menuColorDisabled
 		}
 		g.txtRenderer.SetColor(textColor)
-		g.txtRenderer.Draw(menuHead.option, 300, locY)
-		locY += 50
+		g.txtRenderer.Draw(menuHead.Label(), winWidth/2, locY)
+		menuHead.rect = hitRect(g, menuHead.Label(), winWidth/2, locY, step)
+		if m.details && menuHead.description != "" {
+			g.txtRenderer.SetSizePx(15)
+			g.txtRenderer.Draw(menuHead.description, winWidth/2, locY+24)
+			g.txtRenderer.SetSizePx(32)
+		}
+		locY += step
 		menuHead = menuHead.next
 	}
+	if below {
+		drawArrow(screen, winWidth/2, locY-step/2+4, false, menuColorInactive)
+	}
+	if !m.details && m.active.description != "" {
+		g.txtRenderer.SetSizePx(15)
+		g.txtRenderer.SetColor(menuColorInactive)
+		g.txtRenderer.Draw(m.active.description, winWidth/2, winHeight-60)
+		g.txtRenderer.SetSizePx(32)
+	}
 }
 
 // World is a Game State that holds all level data for active game
 type World struct {
-	menu   *Menu
-	levels []*LevelData
+	stateHooks
+	menus       *MenuStack
+	planets     []*Planet
+	planet      *Planet
+	levels      []*LevelData
+	worldMap    *WorldMap
+	menuOpen    bool
+	confirmQuit bool
 }
 
 // NewWorld creates a new World with all levels not yet completed
 func NewWorld() *World {
-	world := &World{
-		menu: worldMenu,
-	}
+	world := &World{}
+	world.menus = NewMenuStack(newWorldMenu(world))
 	world.Load(FileSystem)
 	return world
 }
 
-// Load loads all default level data into World
+// Load loads all default planet and level data into World, starting on the first planet
 func (w *World) Load(fs embed.FS) {
-	var levels []*LevelData
-	lvlContent, err := fs.ReadFile("levels.json")
-	if err != nil {
-		log.Fatal("Error when opening file: ", err)
+	w.planets = loadPlanets(fs)
+	w.enter(w.planets[0])
+}
+
+// enter makes p the active planet, applying its map art, bounds and levels
+func (w *World) enter(p *Planet) {
+	log.Printf("Entering planet %s", p.Name)
+	w.planet = p
+	w.levels = p.levels
+	w.worldMap = p.worldMap
+}
+
+// planetNamed finds a planet by name, defaulting to the first planet for saves that predate travel
+func (w *World) planetNamed(name string) *Planet {
+	for _, p := range w.planets {
+		if p.Name == name {
+			return p
+		}
 	}
+	return w.planets[0]
+}
 
-	err = json.Unmarshal(lvlContent, &levels)
-	if err != nil {
-		log.Fatal("Error during Unmarshalling: ", err)
+// allLevels lists the levels of every planet
+func (w *World) allLevels() []*LevelData {
+	var levels []*LevelData
+	for _, p := range w.planets {
+		levels = append(levels, p.levels...)
 	}
+	return levels
+}
 
-	for _, l := range levels {
-		l.icon = levelImages[l.Name][0]
-		l.iconComplete = levelImages[l.Name][1]
-		l.background = levelImages[l.Name][2]
+// travel moves the avatar through the portal to the next planet, once enough levels are complete on this one
+func (w *World) travel(g *Game) {
+	curr := 0
+	for i, p := range w.planets {
+		if p == w.planet {
+			curr = i
+		}
+	}
+	next := (curr + 1) % len(w.planets)
+	if next == curr {
+		return
+	}
+	if next > curr && w.planet.completed() < w.planet.Unlock {
+		g.setNotice(fmt.Sprintf("Complete %d more levels to open the portal", w.planet.Unlock-w.planet.completed()))
+		return
 	}
 
-	w.levels = levels
+	w.enter(w.planets[next])
+	avatar := g.session.avatar
+	avatar.node = ""
+	avatar.route = nil
+	x, y := w.planet.arrival()
+	avatar.setMapLocation(w.planet, x, y)
+	g.setNotice("Welcome to " + w.planet.Name)
 }
 
 // Update changes player location/worldview offset and changes state to Play based on user input
 func (w *World) Update(g *Game) error {
-	/*
-		if inpututil.IsKeyJustPressed(ebiten.KeyS) {
-			saveData := NewSaveData(g)
-			log.Printf("Name is " + saveData.Name)
-			log.Printf(strconv.Itoa(saveData.Lives))
-			saveData.Save(g, playerChar, worldPlayer)
-		}
-	*/
-	// set worldPlayer location and view screen: this should go in Menu->Start New Game
-	if ebiten.IsKeyPressed(ebiten.KeyEscape) {
-		// later, ask confirmation if game not saved since entering World
-		// Options: Save, Quit without Saving
-		log.Printf("Exiting Game")
-		return ErrExit
-	}
-	// radiusCheck is making sure worldPlayer stays within movement radius of planet
-	radiusCheck := math.Sqrt(math.Pow(float64(worldPlayer.xCoord-500-worldPlayer.view.xCoord), 2) + math.Pow(float64(worldPlayer.yCoord-500-worldPlayer.view.yCoord), 2))
-	// 4 directions of worldPlayer movement checks
-	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
-		worldPlayer.navRight(radiusCheck)
-	}
-	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
-		worldPlayer.navLeft(radiusCheck)
+	if w.menuOpen {
+		return w.updateMenu(g)
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyArrowUp) {
-		worldPlayer.navUp(radiusCheck)
+	if actionJustPressed(actionPause) {
+		log.Printf("Opening World options")
+		w.menuOpen = true
+		w.menus.Reset()
+		w.confirmQuit = false
+		return nil
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyArrowDown) {
-		worldPlayer.navDown(radiusCheck)
+	avatar := g.session.avatar
+	if w.worldMap.pathMode() {
+		w.navigatePath(avatar)
+	} else {
+		w.navigateFree(avatar)
 	}
 
-	worldPlayerBox := image.Rect(worldPlayer.xCoord, worldPlayer.yCoord, worldPlayer.xCoord+worldCharWidth, worldPlayer.yCoord+worldCharHeight)
+	avatarBox := image.Rect(avatar.xCoord, avatar.yCoord, avatar.xCoord+worldCharWidth, avatar.yCoord+worldCharHeight)
 	// locations of levels on World, checking whether conditions are met to enter the level
 	for _, l := range w.levels {
-		if worldPlayerBox.Overlaps(image.Rect(l.WorldX+worldPlayer.view.xCoord, l.WorldY+worldPlayer.view.yCoord, l.WorldX+worldPlayer.view.xCoord+150, l.WorldY+worldPlayer.view.yCoord+150)) &&
-			ebiten.IsKeyPressed(ebiten.KeyEnter) &&
+		if avatarBox.Overlaps(image.Rect(l.WorldX+avatar.view.xCoord, l.WorldY+avatar.view.yCoord, l.WorldX+avatar.view.xCoord+150, l.WorldY+avatar.view.yCoord+150)) &&
+			actionPressed(actionConfirm) &&
+			!avatar.walking() &&
 			l.Complete == false {
 
-			levelWidth, levelHeight = l.background.Size()
-			playerChar.resetView()
-			playerChar.setLocation(l.PlayerX, l.PlayerY)
-			playerChar.hpCurrent = playerChar.hpTotal
-			levelSetup(l, playerChar.view.xCoord, playerChar.view.yCoord)
-			playLevel := NewPlay(l)
-			g.state["Play"] = playLevel
-			pauseEntry := NewPause("message", l.Message[0])
-			g.state["Pause"] = pauseEntry
-			g.mode = "Pause"
+			seed := g.session.levelSeed(l.Name)
+			log.Printf("Setting up %s with seed %d", l.Name, seed)
+			level := sim.NewLevel(l.spec(), g.session.player, seed)
+			g.transitionTo(transitionEnterLevel, func() {
+				g.push(NewPlay(l, level))
+				g.push(NewPause("message", l.Message[0]))
+			})
+			return nil
 		}
 	}
+	if avatarBox.Overlaps(w.planet.portalBox(avatar.view)) &&
+		actionJustPressed(actionConfirm) &&
+		!avatar.walking() {
+		w.travel(g)
+	}
 	return nil
 }
 
+// updateMenu handles the World options overlay
+func (w *World) updateMenu(g *Game) error {
+	menu := w.menus.Top()
+	switch {
+	case actionJustPressed(actionBack) || backPressed():
+		if w.menus.Len() > 1 {
+			w.menus.Pop()
+		} else {
+			w.menuOpen = false
+		}
+		return nil
+	case actionRepeating(actionMoveDown):
+		menu.Next()
+		w.confirmQuit = false
+	case actionRepeating(actionMoveUp):
+		menu.Prev()
+		w.confirmQuit = false
+	case actionJustPressed(actionPageDown):
+		menu.PageDown()
+		w.confirmQuit = false
+	case actionJustPressed(actionPageUp):
+		menu.PageUp()
+		w.confirmQuit = false
+	case actionJustPressed(actionMoveRight):
+		menu.Adjust(g, 1)
+	case actionJustPressed(actionMoveLeft):
+		menu.Adjust(g, -1)
+	case actionJustPressed(actionConfirm):
+		return menu.Select(g)
+	}
+	return menu.UpdatePointer(g)
+}
+
+func (w *World) menuStack() *MenuStack {
+	return w.menus
+}
+
+// save writes progress to the game's save slot
+func (w *World) save(g *Game) error {
+	saveData := NewSaveData(g)
+	if err := saveData.Save(); err != nil {
+		g.setNotice("Save failed: " + err.Error())
+		return nil
+	}
+	g.session.lastSave = saveData
+	g.session.slot = saveData.slot
+	refreshLoadMenu(g)
+	g.setNotice(fmt.Sprintf("Game saved to slot %d", saveData.slot))
+	return nil
+}
+
+// showStats opens the progress of the active game
+func (w *World) showStats(g *Game) error {
+	w.menus.Push(newPage("Stats", w.stats(g)))
+	return nil
+}
+
+// stats lists player name, score, lives, level completion and play time
+func (w *World) stats(g *Game) []string {
+	lines := []string{
+		"Name: " + g.session.player.Name,
+		"Score: " + strconv.Itoa(g.session.score),
+		"Lives: " + strconv.Itoa(g.session.player.Lives),
+	}
+	for _, p := range w.planets {
+		lines = append(lines, fmt.Sprintf("%s: %d/%d levels", p.Name, p.completed(), len(p.levels)))
+	}
+	return append(lines, "Play Time: "+playTime(g.session.playTime))
+}
+
+// mainMenu closes the options and returns to Title
+func (w *World) mainMenu(g *Game) error {
+	w.menuOpen = false
+	return showMainMenu(g)
+}
+
+// quit exits the game, asking for a second Quit when progress is unsaved
+func (w *World) quit(g *Game) error {
+	if w.unsaved(g) && !w.confirmQuit {
+		w.confirmQuit = true
+		g.setNotice("Progress not saved! Select Quit again to exit")
+		return nil
+	}
+	log.Printf("Exiting Game")
+	return ErrExit
+}
+
+// unsaved reports whether progress has been made since the game was last saved or loaded
+func (w *World) unsaved(g *Game) bool {
+	last := g.session.lastSave
+	return last == nil || !last.sameProgress(NewSaveData(g))
+}
+
+// navigateFree moves the avatar in 4 directions within movement radius of planet
+func (w *World) navigateFree(avatar *WorldChar) {
+	// radiusCheck is making sure the avatar stays within movement radius of planet
+	centerX, centerY := w.planet.center()
+	radiusCheck := math.Sqrt(math.Pow(float64(avatar.xCoord-centerX-avatar.view.xCoord), 2) + math.Pow(float64(avatar.yCoord-centerY-avatar.view.yCoord), 2))
+	// 4 directions of avatar movement checks
+	if actionPressed(actionMoveRight) {
+		avatar.navRight(radiusCheck, w.planet)
+	}
+	if actionPressed(actionMoveLeft) {
+		avatar.navLeft(radiusCheck, w.planet)
+	}
+	if actionPressed(actionMoveUp) {
+		avatar.navUp(radiusCheck, w.planet)
+	}
+	if actionPressed(actionMoveDown) {
+		avatar.navDown(radiusCheck, w.planet)
+	}
+}
+
+// navigatePath walks the avatar along revealed paths between map nodes, one key press per path
+func (w *World) navigatePath(avatar *WorldChar) {
+	w.worldMap.reveal(w.levels)
+	if avatar.node == "" {
+		x, y := avatar.mapLocation()
+		start := w.worldMap.nearestNode(x, y)
+		avatar.node = start.Name
+		avatar.setMapLocation(w.planet, start.X, start.Y)
+	}
+	if avatar.walking() {
+		avatar.walk(w.planet)
+		return
+	}
+
+	direction := ""
+	switch {
+	case actionJustPressed(actionMoveRight):
+		direction = "right"
+	case actionJustPressed(actionMoveLeft):
+		direction = "left"
+	case actionJustPressed(actionMoveUp):
+		direction = "up"
+	case actionJustPressed(actionMoveDown):
+		direction = "down"
+	default:
+		return
+	}
+	route, dest := w.worldMap.route(avatar.node, direction)
+	if route == nil {
+		return
+	}
+	log.Printf("Walking from %s to %s", avatar.node, dest)
+	avatar.route = route
+	avatar.destination = dest
+	avatar.direction = direction
+}
+
 // Draw displays player on World map
 func (w *World) Draw(screen *ebiten.Image, g *Game) {
+	avatar := g.session.avatar
 	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(float64(worldPlayer.view.xCoord), float64(worldPlayer.view.yCoord))
-	screen.DrawImage(world, op)
+	op.GeoM.Translate(float64(avatar.view.xCoord), float64(avatar.view.yCoord))
+	screen.DrawImage(w.planet.image, op)
 	for _, l := range w.levels {
 		levelIcon := l.icon
 		if l.Complete == true {
@@ -300,196 +563,213 @@ func (w *World) Draw(screen *ebiten.Image, g *Game) {
 		}
 		lop := &ebiten.DrawImageOptions{}
 		lop.GeoM.Translate(float64(l.WorldX), float64(l.WorldY))
-		world.DrawImage(levelIcon, lop)
+		w.planet.image.DrawImage(levelIcon, lop)
+	}
+	pop := &ebiten.DrawImageOptions{}
+	pop.GeoM.Translate(float64(w.planet.PortalX+avatar.view.xCoord), float64(w.planet.PortalY+avatar.view.yCoord))
+	px := (g.count / 5) % portalFrameCount * portalWidth
+	screen.DrawImage(portal.SubImage(image.Rect(px, 0, px+portalWidth, portalHeight)).(*ebiten.Image), pop)
+
+	avatarFrame := image.Rect(0, 0, 50, 50)
+	if w.worldMap.pathMode() {
+		w.drawPaths(screen, avatar.view)
+		avatarFrame = avatar.frame(g.count)
 	}
 	op = &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(float64(worldPlayer.xCoord), float64(worldPlayer.yCoord))
-	screen.DrawImage(worldPlayer.sprite.SubImage(image.Rect(0, 0, 50, 50)).(*ebiten.Image), op)
-}
+	op.GeoM.Translate(float64(avatar.xCoord), float64(avatar.yCoord))
+	screen.DrawImage(avatar.sprite.SubImage(avatarFrame).(*ebiten.Image), op)
 
-// Play contains data for active level
-type Play struct {
-	level *LevelData
-	gem   bool
+	if w.menuOpen {
+		w.drawMenu(screen, g)
+	}
 }
 
-// NewPlay creates new Play for a given level on entry
-func NewPlay(l *LevelData) *Play {
-	play := &Play{
-		level: l,
+// drawMenu overlays the World options in a message box, or over the whole screen for submenus like Stats and Settings
+func (w *World) drawMenu(screen *ebiten.Image, g *Game) {
+	menu := w.menus.Top()
+	if w.menus.Len() > 1 {
+		ebitenutil.DrawRect(screen, 0, 0, winWidth, winHeight, overlayColor)
+		drawMenuPage(screen, g, menu)
+		return
 	}
-	return play
-}
 
-// Update is the main gameplay function. Changes score, player health/lives based on user input and collisions
-func (p *Play) Update(g *Game) error {
-	// sprite frames for different things -- handle differently later
-	portalFrame = (g.count / 5) % portalFrameCount
-	hazardFrame = (g.count / 5) % hazardFrameCount
-	creatureFrame = (g.count / 5) % creatureFrameCount
-
-	treasureTypeList[3].frame = (g.count / 5) % treasureTypeList[3].frameCt
-	treasureTypeList[4].frame = (g.count / 5) % treasureTypeList[4].frameCt
-
-	// player sprite frame reset
-	if inpututil.IsKeyJustReleased(ebiten.KeyArrowRight) || inpututil.IsKeyJustReleased(ebiten.KeyArrowLeft) {
-		currentFrame = defaultFrame
-	}
-
-	baseView := [2]int{playerChar.view.xCoord, playerChar.view.yCoord}
-	// 2 direction movement
-	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
-		currentFrame = (g.count / 5) % frameCount
-		playerChar.moveRight()
-	}
-	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
-		currentFrame = (g.count / 5) % frameCount
-		playerChar.moveLeft()
-	}
-	// if view x changed, update x location of on-screen objects
-	if baseView[0] != playerChar.view.xCoord {
-		delta := playerChar.view.xCoord - baseView[0]
-		for _, h := range hazardList {
-			h.xCoord += delta
-		}
-		for _, c := range creatureList {
-			c.xCoord += delta
+	boxW, boxH := messageBox.Size()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64((winWidth-boxW)/2), float64((winHeight-boxH)/2))
+	screen.DrawImage(messageBox, op)
+
+	g.txtRenderer.SetAlign(etxt.YCenter, etxt.XCenter)
+	g.txtRenderer.SetTarget(screen)
+	g.txtRenderer.SetSizePx(28)
+	g.txtRenderer.SetColor(messageBoxColor)
+	g.txtRenderer.Draw(menu.header, winWidth/2, winHeight/2-70)
+
+	g.txtRenderer.SetSizePx(22)
+	item := menu.head
+	locY := winHeight/2 - 40
+	for i := menu.length; i > 0; i-- {
+		textColor = messageBoxColor
+		if item == menu.active {
+			textColor = menuColorActive
 		}
-		for _, t := range treasureList {
-			t.xCoord += delta
+		if !item.Enabled() {
+			textColor = menuColorDisabled
 		}
+		g.txtRenderer.SetColor(textColor)
+		g.txtRenderer.Draw(item.Label(), winWidth/2, locY)
+		item.rect = hitRect(g, item.Label(), winWidth/2, locY, 26)
+		locY += 26
+		item = item.next
 	}
-
-	// jump logic
-	//		if inpututil.IsKeyJustPressed(ebiten.KeySpace) && playerChar.yVelo == gravity { // && a pixel beneath playerChar is enviroBlock
-	//			playerChar.yVelo = -gravity
-	//		}
-
-	if ebiten.IsKeyPressed(ebiten.KeySpace) {
-		log.Printf("KeyPress Duration: %d", inpututil.KeyPressDuration(ebiten.KeySpace))
-		log.Printf("Character status: %s", playerChar.status)
-		playerChar.jump(inpututil.KeyPressDuration(ebiten.KeySpace))
+	if menu.active.description != "" {
+		g.txtRenderer.SetSizePx(15)
+		g.txtRenderer.SetColor(messageBoxColor)
+		g.txtRenderer.Draw(menu.active.description, winWidth/2, locY-10)
 	}
+	g.txtRenderer.SetSizePx(32)
+}
 
-	if playerChar.yVelo < gravity {
-		// screen movement vs player movement
-		if (playerChar.yCoord < 160 && playerChar.view.yCoord-playerChar.yVelo < 0 && playerChar.yVelo < 0) ||
-			(playerChar.yCoord > 160 && playerChar.view.yCoord-playerChar.yVelo > -120 && playerChar.yVelo > 0) {
-			playerChar.view.yCoord -= playerChar.yVelo
-			for _, h := range hazardList {
-				h.yCoord -= playerChar.yVelo
-			}
-			for _, c := range creatureList {
-				c.yCoord -= playerChar.yVelo
-			}
-			for _, t := range treasureList {
-				t.yCoord -= playerChar.yVelo
-			}
-		} else {
-			playerChar.yCoord += playerChar.yVelo
-		}
-
-		playerChar.yVelo++
-
-		if playerChar.yVelo >= 0 {
-			playerCharBase := (playerChar.yCoord - playerChar.view.yCoord + playerCharHeight + 1) / 50 // checks immediately BELOW base of sprite
-			playerCharLeft := (playerChar.xCoord - playerChar.view.xCoord) / 50
-			playerCharRight := (playerChar.xCoord - playerChar.view.xCoord + playerCharWidth) / 50
-			if levelMap[0][(playerCharBase)*tileXCount+playerCharLeft] == 1 || levelMap[0][(playerCharBase)*tileXCount+playerCharRight] == 1 {
-				playerChar.yCoord = (playerCharBase * 50) - 50 + playerChar.view.yCoord
-				playerChar.yVelo = gravity
-			}
+// drawPaths dots revealed paths onto the World, through the center of the avatar's footing
+func (w *World) drawPaths(screen *ebiten.Image, view *Viewer) {
+	offX := float64(view.xCoord + worldCharWidth/2 - 2)
+	offY := float64(view.yCoord + worldCharHeight/2 - 2)
+	for _, p := range w.worldMap.Paths {
+		if !p.revealed {
+			continue
 		}
-	}
-	playerCharBase := (playerChar.yCoord - playerChar.view.yCoord + playerCharHeight + 1) / 50 // checks immediately BELOW base of sprite
-	playerCharLeft := (playerChar.xCoord - playerChar.view.xCoord) / 50
-	playerCharRight := (playerChar.xCoord - playerChar.view.xCoord + playerCharWidth) / 50
-	// gravity fixer
-	if playerChar.status != "ground" && levelMap[0][(playerCharBase*tileXCount)+playerCharLeft] != 1 && levelMap[0][(playerCharBase*tileXCount)+playerCharRight] != 1 {
-		switch {
-		case playerChar.view.yCoord > -120 && playerChar.yCoord > 160:
-			playerChar.view.yCoord -= 3
-			for _, h := range hazardList {
-				h.yCoord -= 3
-			}
-			for _, c := range creatureList {
-				c.yCoord -= 3
-			}
-			for _, t := range treasureList {
-				t.yCoord -= 3
+		for i := 1; i < len(p.Points); i++ {
+			ax, ay := float64(p.Points[i-1][0]), float64(p.Points[i-1][1])
+			bx, by := float64(p.Points[i][0]), float64(p.Points[i][1])
+			dots := int(math.Hypot(bx-ax, by-ay)) / pathDotGap
+			for d := 0; d <= dots; d++ {
+				t := 0.0
+				if dots > 0 {
+					t = float64(d) / float64(dots)
+				}
+				ebitenutil.DrawRect(screen, ax+(bx-ax)*t+offX, ay+(by-ay)*t+offY, 4, 4, mapPathColor)
 			}
-		default:
-			playerChar.yCoord += 3
 		}
 	}
+}
 
-	playerCharFreshBase := (playerChar.yCoord - playerChar.view.yCoord + playerCharHeight + 1) / 50 // checks immediately BELOW base of sprite
-	if levelMap[0][(playerCharFreshBase*tileXCount)+playerCharLeft] == 1 || levelMap[0][(playerCharFreshBase*tileXCount)+playerCharRight] == 1 {
-		playerChar.status = "ground"
-	} else if playerChar.yVelo == gravity {
-		playerChar.status = "fall"
+// Play is a Game State that runs a level's simulation from the player's input and draws it
+type Play struct {
+	stateHooks
+	data      *LevelData
+	level     *sim.Level
+	recording *sim.Replay // this attempt's input so far, nil for a level resumed from a snapshot
+	replay    *sim.Replay // input to play back instead of the player's, in replay mode
+}
+
+// NewPlay creates new Play for a level on entry, recording the player's input
+func NewPlay(data *LevelData, level *sim.Level) *Play {
+	play := &Play{
+		data:      data,
+		level:     level,
+		recording: sim.NewReplay(gameVersion, level),
 	}
+	return play
+}
 
-	creatureMovement()
+// NewReplayPlay creates a Play that feeds a replay's input into level, which must be new and started with the replay's seed
+func NewReplayPlay(data *LevelData, level *sim.Level, replay *sim.Replay) *Play {
+	return &Play{
+		data:   data,
+		level:  level,
+		replay: replay,
+	}
+}
 
-	playerBox := image.Rect(playerChar.xCoord, playerChar.yCoord, playerChar.xCoord+playerCharWidth, playerChar.yCoord+playerCharWidth)
+// Update advances the level one tick with the current keys, then pauses on death or leaves on completion
+func (p *Play) Update(g *Game) error {
+	if p.replay != nil {
+		return p.updateReplay(g)
+	}
+	if actionJustPressed(actionPause) && p.level.Player.Status != "dying" {
+		g.push(NewPause("suspend", "Paused"))
+		return nil
+	}
 
-	for i, t := range treasureList {
-		treasureBox := image.Rect(t.xCoord, t.yCoord, t.xCoord+50, t.yCoord+50)
-		if playerBox.Overlaps(treasureBox) {
-			g.score += t.value
-			if t.name == "Portal Gem" {
-				p.gem = true
-			}
-			treasureList = append(treasureList[0:i], treasureList[i+1:]...)
+	in := playInput()
+	if p.recording != nil {
+		p.recording.Record(in)
+		if len(p.recording.Inputs)%replayFlushTicks == 0 {
+			saveReplay(p.recording)
 		}
 	}
+	result := p.level.Tick(in)
+	if result.Points > 0 {
+		log.Printf("Collected treasure worth %d", result.Points)
+	}
+	g.session.score += result.Points
+	switch {
+	case result.Died:
+		log.Printf("Player died")
+		p.endRecording()
+		g.timer = 30
+		g.push(NewPause("", ""))
+	case result.Complete:
+		p.endRecording()
+		p.data.Complete = true
+		log.Printf("Level complete")
+		g.transitionTo(transitionLeaveLevel, g.pop)
+	}
+	return nil
+}
 
-	for _, h := range hazardList {
-		hazardBox := image.Rect(h.xCoord, h.yCoord, h.xCoord+50, h.yCoord+50)
-		if playerBox.Overlaps(hazardBox) {
-			playerChar.death()
-			g.mode = "Pause"
-			g.timer = 30
-		}
+// updateReplay advances the level with the replay's input for this tick, returning to Title when it runs out or the attempt ends; Back stops it early
+func (p *Play) updateReplay(g *Game) error {
+	if actionJustPressed(actionBack) || actionJustPressed(actionPause) || p.level.Ticks >= len(p.replay.Inputs) {
+		g.setNotice("Replay finished")
+		return showMainMenu(g)
 	}
+	result := p.level.Tick(p.replay.Inputs[p.level.Ticks])
+	g.session.score += result.Points
+	if result.Died || result.Complete {
+		log.Printf("Replay ended at tick %d: died %t, complete %t", p.level.Ticks, result.Died, result.Complete)
+		g.setNotice("Replay finished")
+		return showMainMenu(g)
+	}
+	return nil
+}
 
-	for _, c := range creatureList {
-		creatureBox := image.Rect(c.xCoord, c.yCoord, c.xCoord+50, c.yCoord+50)
-		if playerBox.Overlaps(creatureBox) {
-			playerChar.death()
-			g.mode = "Pause"
-			g.timer = 30
-		}
+// Exit saves the attempt's input however the level is left, even by quitting or closing the window
+func (p *Play) Exit(g *Game) {
+	p.endRecording()
+}
+
+// endRecording saves the attempt's input, once, when it ends
+func (p *Play) endRecording() {
+	if p.recording == nil {
+		return
 	}
+	saveReplay(p.recording)
+	p.recording = nil
+}
 
-	if p.gem &&
-		playerBox.Overlaps(image.Rect(p.level.ExitX+playerChar.view.xCoord, p.level.ExitY+playerChar.view.yCoord,
-			p.level.ExitX+portalWidth+playerChar.view.xCoord, p.level.ExitY+portalHeight+playerChar.view.yCoord)) {
-		p.level.Complete = true
-		p.gem = false
-		clearLevel()
-		log.Print("Just hit the portal")
-		//levelComplete()
-		log.Printf("Level complete")
-		g.mode = "World"
+// playInput reads the bound actions into a simulation input
+func playInput() sim.Input {
+	return sim.Input{
+		Left:  actionPressed(actionMoveLeft),
+		Right: actionPressed(actionMoveRight),
+		Jump:  actionDuration(actionJump),
 	}
-	/*
-		if ebiten.IsKeyPressed(ebiten.KeyQ) {
-			g.state = "Pause"
-		}
-	*/
-	return nil
 }
 
 // Draw displays level game play
 func (p *Play) Draw(screen *ebiten.Image, g *Game) {
+	l := p.level
+	pc := l.Player
+	frame := defaultFrame
+	if pc.Walking {
+		frame = (g.count / 5) % frameCount
+	}
 	lvlOp := &ebiten.DrawImageOptions{}
-	lvlOp.GeoM.Translate(float64(playerChar.view.xCoord), float64(playerChar.view.yCoord))
-	screen.DrawImage(p.level.background, lvlOp)
+	lvlOp.GeoM.Translate(float64(pc.View.X), float64(pc.View.Y))
+	screen.DrawImage(p.data.background, lvlOp)
 
 	switch {
-	case playerChar.status == "dying":
+	case pc.Status == "dying":
 		mOp := &ebiten.DrawImageOptions{}
 		for i := 0; i < playerCharHeight; i += playerCharHeight / 8 {
 			wobble := 30 - g.timer
@@ -497,53 +777,54 @@ func (p *Play) Draw(screen *ebiten.Image, g *Game) {
 				wobble *= -1
 			}
 			mOp.GeoM.Reset()
-			mOp.GeoM.Translate(float64(playerChar.xCoord+wobble), float64(playerChar.yCoord+i))
-			cx, cy := currentFrame*playerCharWidth, playerChar.facing
-			screen.DrawImage(playerChar.sprite.SubImage(image.Rect(cx, cy+i, cx+playerCharWidth, cy+i+6)).(*ebiten.Image), mOp)
+			mOp.GeoM.Translate(float64(pc.X+wobble), float64(pc.Y+i))
+			cx, cy := frame*playerCharWidth, pc.Facing
+			screen.DrawImage(spriteSheet.SubImage(image.Rect(cx, cy+i, cx+playerCharWidth, cy+i+6)).(*ebiten.Image), mOp)
 		}
 	default:
 		mOp := &ebiten.DrawImageOptions{}
-		mOp.GeoM.Translate(float64(playerChar.xCoord), float64(playerChar.yCoord))
-		cx, cy := currentFrame*playerCharWidth, playerChar.facing
-		screen.DrawImage(playerChar.sprite.SubImage(image.Rect(cx, cy, cx+playerCharWidth, cy+playerCharHeight)).(*ebiten.Image), mOp)
+		mOp.GeoM.Translate(float64(pc.X), float64(pc.Y))
+		cx, cy := frame*playerCharWidth, pc.Facing
+		screen.DrawImage(spriteSheet.SubImage(image.Rect(cx, cy, cx+playerCharWidth, cy+playerCharHeight)).(*ebiten.Image), mOp)
 	}
 
-	for _, e := range enviroList {
+	for _, b := range l.Bricks {
 		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(float64(e.xCoord), float64(e.yCoord))
-		p.level.background.DrawImage(e.sprite, op)
+		op.GeoM.Translate(float64(b.X), float64(b.Y))
+		p.data.background.DrawImage(brick, op)
 	}
-	if p.gem == true {
+	if l.Gem == true {
 		top := &ebiten.DrawImageOptions{}
-		top.GeoM.Translate(float64(p.level.ExitX+playerChar.view.xCoord), float64(p.level.ExitY+playerChar.view.yCoord))
-		px := portalFrame * 100
+		top.GeoM.Translate(float64(p.data.ExitX+pc.View.X), float64(p.data.ExitY+pc.View.Y))
+		px := (g.count / 5) % portalFrameCount * portalWidth
 		screen.DrawImage(portal.SubImage(image.Rect(px, 0, px+100, 150)).(*ebiten.Image), top)
 	}
-	for _, h := range hazardList {
+	for _, h := range l.Hazards {
 		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(float64(h.xCoord), float64(h.yCoord))
-		hx := hazardFrame * 50
-		screen.DrawImage(h.sprite.SubImage(image.Rect(hx, 0, hx+50, 50)).(*ebiten.Image), op)
+		op.GeoM.Translate(float64(h.X), float64(h.Y))
+		hx := (g.count / 5) % hazardFrameCount * 50
+		screen.DrawImage(hazard.SubImage(image.Rect(hx, 0, hx+50, 50)).(*ebiten.Image), op)
 	}
 
-	for _, c := range creatureList {
+	for _, c := range l.Creatures {
 		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(float64(c.xCoord), float64(c.yCoord))
-		cx, cy := creatureFrame*50, c.facing
-		screen.DrawImage(c.sprite.SubImage(image.Rect(cx, cy, cx+50, cy+50)).(*ebiten.Image), op)
+		op.GeoM.Translate(float64(c.X), float64(c.Y))
+		cx, cy := (g.count/5)%creatureFrameCount*50, c.Facing
+		screen.DrawImage(creature.SubImage(image.Rect(cx, cy, cx+50, cy+50)).(*ebiten.Image), op)
 	}
 
-	for _, t := range treasureList {
-		xOffset := (blockHW - t.width) / 2
-		yOffset := (blockHW - t.height) / 2
+	for _, t := range l.Treasures {
+		tt := treasureTypeList[t.ID]
+		xOffset := (blockHW - tt.width) / 2
+		yOffset := (blockHW - tt.height) / 2
 		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(float64(t.xCoord+xOffset), float64(t.yCoord+yOffset))
-		tx := t.frame * t.width
-		screen.DrawImage(t.sprite.SubImage(image.Rect(tx, 0, tx+t.width, t.height)).(*ebiten.Image), op)
+		op.GeoM.Translate(float64(t.X+xOffset), float64(t.Y+yOffset))
+		tx := (g.count / 5) % tt.frameCt * tt.width
+		screen.DrawImage(tt.sprite.SubImage(image.Rect(tx, 0, tx+tt.width, tt.height)).(*ebiten.Image), op)
 	}
 
 	gx := 0
-	if p.gem == true {
+	if l.Gem == true {
 		gx = 35
 	}
 
@@ -555,19 +836,19 @@ func (p *Play) Draw(screen *ebiten.Image, g *Game) {
 	op.GeoM.Translate(125.0, 64.0)
 	screen.DrawImage(gemCt.SubImage(image.Rect(gx, 0, gx+35, 35)).(*ebiten.Image), op)
 
-	for lx := 0; lx < playerChar.lives-1; lx++ {
+	for lx := 0; lx < pc.Lives-1; lx++ {
 		op = &ebiten.DrawImageOptions{}
 		op.GeoM.Translate(21.0+float64(lx*20), 64.0)
 		screen.DrawImage(livesCt, op)
 	}
 
-	pointsCt := strconv.Itoa(g.score)
+	pointsCt := strconv.Itoa(g.session.score)
 	g.txtRenderer.SetTarget(screen)
 	g.txtRenderer.SetColor(scoreDisplayColor)
 	g.txtRenderer.SetAlign(etxt.Top, etxt.Right)
 	g.txtRenderer.Draw(pointsCt, 160, 16)
 
-	if playerChar.status == "totally dead" {
+	if pc.Status == "totally dead" {
 		overOp := &ebiten.DrawImageOptions{}
 		screen.DrawImage(gameOverMessage, overOp)
 	}
@@ -575,6 +856,7 @@ func (p *Play) Draw(screen *ebiten.Image, g *Game) {
 
 // Pause is a Game State that halts other game logic
 type Pause struct {
+	stateHooks
 	mode    string
 	message string
 	options *Menu
@@ -586,10 +868,21 @@ func NewPause(mod, msg string) *Pause {
 		mode:    mod,
 		message: msg,
 	}
+	if mod == "suspend" {
+		p.options = NewMenu([]*MenuItem{
+			NewMenuItem("Resume", p.resume),
+			{option: "Suspend and Save", description: "Save this level to finish later", action: p.suspend},
+		})
+	}
 	p.FormatMessage()
 	return p
 }
 
+// Overlay draws Pause over the level in Play
+func (p *Pause) Overlay() bool {
+	return true
+}
+
 // FormatMessage adds newlines to Pause.message to fit into messageBox
 func (p *Pause) FormatMessage() {
 	maxLineLen := 20 // adjust based on txt size, msgbox width
@@ -618,43 +911,83 @@ func (p *Pause) FormatMessage() {
 
 // Update only updates playerChar status for death animation and Game Over screen
 func (p *Pause) Update(g *Game) error {
+	pc := g.session.player
 	switch {
 	case p.mode == "message":
-		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
-			p.mode = ""
-			g.mode = "Play"
+		_, clicked := pointerJustPressed()
+		if actionJustPressed(actionConfirm) || clicked {
+			p.resume(g)
 		}
-	case playerChar.status == "totally dead":
-		if ebiten.IsKeyPressed(ebiten.KeyEnter) {
-			g.mode = "Title"
-			clearLevel()
-			playerChar.status = "ground"
+	case p.mode == "suspend":
+		switch {
+		case actionJustPressed(actionPause) || actionJustPressed(actionBack) || backPressed():
+			return p.resume(g)
+		case actionRepeating(actionMoveDown):
+			p.options.Next()
+		case actionRepeating(actionMoveUp):
+			p.options.Prev()
+		case actionJustPressed(actionConfirm):
+			return p.options.Select(g)
+		}
+		return p.options.UpdatePointer(g)
+	case pc.Status == "totally dead":
+		_, clicked := pointerJustPressed()
+		if actionPressed(actionConfirm) || clicked {
+			showMainMenu(g)
+			pc.Status = "ground"
 		}
-	case playerChar.status == "dying" && g.timer <= 0:
-		if playerChar.lives <= 0 {
-			playerChar.status = "totally dead"
+	case pc.Status == "dying" && g.timer <= 0:
+		if pc.Lives <= 0 {
+			pc.Status = "totally dead"
 		}
-		if playerChar.lives > 0 {
-			clearLevel()
-			g.mode = "World"
+		if pc.Lives > 0 {
+			world, ok := findState[*World](g)
+			if !ok {
+				return showMainMenu(g)
+			}
+			g.transitionTo(transitionLoseLife, func() { g.popTo(world) })
 		}
-	case playerChar.status == "dying" && g.timer > 0:
+	case pc.Status == "dying" && g.timer > 0:
 		g.timer--
 	default:
-		if ebiten.IsKeyPressed(ebiten.KeyEscape) {
-			g.mode = "Play"
+		if actionPressed(actionPause) {
+			g.pop()
 		}
 	}
 	return nil
 }
 
-// Draw isn't implemented yet
-func (p *Pause) Draw(screen *ebiten.Image, g *Game) {
-	// TODO
-	// overlays, based on what the pause message and options are
+// resume returns to the level in progress
+func (p *Pause) resume(g *Game) error {
+	g.pop()
+	return nil
+}
 
+// suspend saves the level in progress along with the game, then returns to Title
+func (p *Pause) suspend(g *Game) error {
+	play, ok := findState[*Play](g)
+	if !ok {
+		return nil
+	}
+	play.endRecording()
+	saveData := NewSaveData(g)
+	saveData.Suspended = NewLevelSnapshot(play.level)
+	if err := saveData.Save(); err != nil {
+		g.setNotice("Suspend failed: " + err.Error())
+		return nil
+	}
+	g.session.lastSave = saveData
+	g.session.slot = saveData.slot
+	refreshLoadMenu(g)
+	g.transitionTo(transitionToTitle, func() { g.reset(NewTitle()) })
+	g.setNotice(fmt.Sprintf("Suspended %s to slot %d", play.data.Name, saveData.slot))
+	return nil
+}
+
+// Draw overlays the pause message, and options when the pause has them
+func (p *Pause) Draw(screen *ebiten.Image, g *Game) {
 	switch {
-	case p.mode == "message":
+	case p.mode == "message" || p.mode == "suspend":
 		// draw box image
 		boxW, boxH := messageBox.Size()
 		op := &ebiten.DrawImageOptions{}
@@ -665,50 +998,29 @@ func (p *Pause) Draw(screen *ebiten.Image, g *Game) {
 		g.txtRenderer.SetSizePx(28)
 		g.txtRenderer.SetTarget(screen)
 		g.txtRenderer.SetColor(messageBoxColor)
-		g.txtRenderer.Draw(p.message, winWidth/2, winHeight/2)
-		// draw menu buttons (define these in Update)
-	}
-}
-
-// Info is currently for Acknowledgement page information, holds previous game state
-type Info struct {
-	message  []string
-	previous string
-}
-
-// NewInfo creates new Info struct
-func NewInfo() *Info {
-	info := &Info{}
-	return info
-}
-
-// Update returns to previous game state on [Enter]
-func (i *Info) Update(g *Game) error {
-	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
-		g.mode = i.previous
-	}
-	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-		fmt.Println(i.previous)
-	}
-	return nil
-}
-
-// Draw draws Acknowledgements to screen
-func (i *Info) Draw(screen *ebiten.Image, g *Game) {
-	locY := 100
-	g.txtRenderer.SetAlign(etxt.YCenter, etxt.XCenter)
-	g.txtRenderer.SetTarget(screen)
-	g.txtRenderer.SetColor(menuColorInactive)
-	g.txtRenderer.Draw("Acknowledgements", winWidth/2, locY)
-	locY += 100
-	g.txtRenderer.SetSizePx(18)
-	for _, m := range i.message {
-		g.txtRenderer.Draw(m, winWidth/2, locY)
-		locY += 75
+		if p.mode != "suspend" {
+			g.txtRenderer.Draw(p.message, winWidth/2, winHeight/2)
+			return
+		}
+		g.txtRenderer.Draw(p.message, winWidth/2, winHeight/2-50)
+		g.txtRenderer.SetSizePx(24)
+		item := p.options.head
+		locY := winHeight/2 - 5
+		for i := p.options.length; i > 0; i-- {
+			textColor = messageBoxColor
+			if item == p.options.active {
+				textColor = menuColorActive
+			}
+			g.txtRenderer.SetColor(textColor)
+			g.txtRenderer.Draw(item.Label(), winWidth/2, locY)
+			item.rect = hitRect(g, item.Label(), winWidth/2, locY, 36)
+			locY += 36
+			item = item.next
+		}
+		if p.options.active.description != "" {
+			g.txtRenderer.SetSizePx(15)
+			g.txtRenderer.SetColor(messageBoxColor)
+			g.txtRenderer.Draw(p.options.active.description, winWidth/2, locY)
+		}
 	}
-	locY += 50
-	g.txtRenderer.SetSizePx(32)
-	g.txtRenderer.SetColor(menuColorActive)
-	g.txtRenderer.Draw("Main Menu", winWidth/2, locY)
-
 }
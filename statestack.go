@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// State describes Game State; the state on top of the Game's stack is updated each tick
+type State interface {
+	Update(g *Game) error
+	Draw(screen *ebiten.Image, g *Game)
+
+	Enter(g *Game)   // pushed onto the stack
+	Exit(g *Game)    // popped off the stack, or replaced
+	Suspend(g *Game) // another state pushed on top
+	Resume(g *Game)  // back on top after the state above it popped
+	Overlay() bool   // draws over the states beneath, which are drawn first
+}
+
+// stateHooks gives a State hooks that do nothing and no overlay, to embed in states that only need some of them
+type stateHooks struct{}
+
+func (stateHooks) Enter(g *Game)   {}
+func (stateHooks) Exit(g *Game)    {}
+func (stateHooks) Suspend(g *Game) {}
+func (stateHooks) Resume(g *Game)  {}
+func (stateHooks) Overlay() bool   { return false }
+
+// stateName gives a State's type for logging, like "*main.Play"
+func stateName(s State) string {
+	return fmt.Sprintf("%T", s)
+}
+
+// top gives the state receiving updates
+func (g *Game) top() State {
+	if len(g.states) == 0 {
+		return nil
+	}
+	return g.states[len(g.states)-1]
+}
+
+// push puts s on top of the current state, which stays beneath it to resume later
+func (g *Game) push(s State) {
+	if top := g.top(); top != nil {
+		top.Suspend(g)
+	}
+	log.Printf("Entering state %s", stateName(s))
+	g.states = append(g.states, s)
+	s.Enter(g)
+}
+
+// pop leaves the top state and resumes the one beneath it
+func (g *Game) pop() {
+	top := g.top()
+	if top == nil {
+		return
+	}
+	log.Printf("Leaving state %s", stateName(top))
+	top.Exit(g)
+	g.states = g.states[:len(g.states)-1]
+	if next := g.top(); next != nil {
+		next.Resume(g)
+	}
+}
+
+// replace swaps the top state for s
+func (g *Game) replace(s State) {
+	if top := g.top(); top != nil {
+		log.Printf("Leaving state %s", stateName(top))
+		top.Exit(g)
+		g.states = g.states[:len(g.states)-1]
+	}
+	log.Printf("Entering state %s", stateName(s))
+	g.states = append(g.states, s)
+	s.Enter(g)
+}
+
+// reset leaves every state, starting over from s
+func (g *Game) reset(s State) {
+	g.exitAll()
+	g.push(s)
+}
+
+// exitAll leaves every state, top first, as when the game shuts down
+func (g *Game) exitAll() {
+	for len(g.states) > 0 {
+		top := g.top()
+		log.Printf("Leaving state %s", stateName(top))
+		top.Exit(g)
+		g.states = g.states[:len(g.states)-1]
+	}
+}
+
+// popTo leaves states until the one on top is s; when s isn't on the stack, nothing is left, rather than emptying it
+func (g *Game) popTo(s State) {
+	found := false
+	for _, st := range g.states {
+		found = found || st == s
+	}
+	if !found {
+		log.Printf("State %s is not on the stack, staying in %s", stateName(s), stateName(g.top()))
+		return
+	}
+	for g.top() != s {
+		g.pop()
+	}
+}
+
+// findState gives the topmost state of type T on the stack, like the World beneath a level in Play
+func findState[T State](g *Game) (T, bool) {
+	for i := len(g.states) - 1; i >= 0; i-- {
+		if s, ok := g.states[i].(T); ok {
+			return s, true
+		}
+	}
+	var none T
+	return none, false
+}
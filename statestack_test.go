@@ -0,0 +1,101 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// recorder is a State that notes each hook called on it
+type recorder struct {
+	name    string
+	log     *[]string
+	overlay bool
+}
+
+func (r *recorder) Update(g *Game) error               { return nil }
+func (r *recorder) Draw(screen *ebiten.Image, g *Game) {}
+func (r *recorder) Enter(g *Game)                      { *r.log = append(*r.log, r.name+" enter") }
+func (r *recorder) Exit(g *Game)                       { *r.log = append(*r.log, r.name+" exit") }
+func (r *recorder) Suspend(g *Game)                    { *r.log = append(*r.log, r.name+" suspend") }
+func (r *recorder) Resume(g *Game)                     { *r.log = append(*r.log, r.name+" resume") }
+func (r *recorder) Overlay() bool                      { return r.overlay }
+
+func TestStateStackHooks(t *testing.T) {
+	var log []string
+	world := &recorder{name: "world", log: &log}
+	play := &recorder{name: "play", log: &log}
+	pause := &recorder{name: "pause", log: &log, overlay: true}
+	title := &recorder{name: "title", log: &log}
+
+	g := &Game{}
+	g.push(world)
+	g.push(play)
+	g.push(pause)
+	g.pop()
+	g.push(pause)
+	g.popTo(world)
+	g.replace(title)
+
+	want := []string{
+		"world enter",
+		"world suspend", "play enter",
+		"play suspend", "pause enter",
+		"pause exit", "play resume",
+		"play suspend", "pause enter",
+		"pause exit", "play resume", "play exit", "world resume",
+		"world exit", "title enter",
+	}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("hooks called:\n%v\nwant:\n%v", log, want)
+	}
+	if g.top() != title || len(g.states) != 1 {
+		t.Errorf("stack = %v, want just title", g.states)
+	}
+}
+
+func TestResetExitsEveryState(t *testing.T) {
+	var log []string
+	g := &Game{}
+	g.push(&recorder{name: "world", log: &log})
+	g.push(&recorder{name: "play", log: &log})
+	log = nil
+
+	g.reset(&recorder{name: "title", log: &log})
+	want := []string{"play exit", "world exit", "title enter"}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("hooks called %v, want %v", log, want)
+	}
+}
+
+func TestPopToMissingStateKeepsStack(t *testing.T) {
+	var log []string
+	g := &Game{}
+	play := &recorder{name: "play", log: &log}
+	g.push(play)
+	g.push(&recorder{name: "pause", log: &log})
+
+	g.popTo(&recorder{name: "world", log: &log})
+	if len(g.states) != 2 {
+		t.Errorf("popTo a state not on the stack left %d states, want 2", len(g.states))
+	}
+	g.popTo(play)
+	if g.top() != play || len(g.states) != 1 {
+		t.Errorf("popTo play left %v, want just play", g.states)
+	}
+}
+
+func TestFindState(t *testing.T) {
+	g := &Game{}
+	play := &Play{}
+	g.push(play)
+	g.push(&Pause{})
+
+	if found, ok := findState[*Play](g); !ok || found != play {
+		t.Errorf("findState[*Play] = %v, %v", found, ok)
+	}
+	if _, ok := findState[*World](g); ok {
+		t.Error("found a World that was never pushed")
+	}
+}
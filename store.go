@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SaveStore keeps named documents (save slots, settings), so game code never touches the filesystem directly
+type SaveStore interface {
+	List() ([]string, error)
+	Load(name string) ([]byte, error)
+	Save(name string, data []byte) error
+	Delete(name string) error
+	ModTime(name string) (time.Time, error) // when a document was last written
+}
+
+// FileStore is a SaveStore backed by a directory on disk
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a FileStore in dir; the directory is created on first Save if it doesn't exist
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// List gives the names of documents in the directory
+func (f *FileStore) List() ([]string, error) {
+	names := []string{}
+	files, err := os.ReadDir(f.dir)
+	if os.IsNotExist(err) {
+		return names, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range files {
+		if !item.IsDir() && !strings.HasSuffix(item.Name(), ".tmp") {
+			names = append(names, item.Name())
+		}
+	}
+	return names, nil
+}
+
+// Load reads a document
+func (f *FileStore) Load(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(f.dir, name))
+}
+
+// Save writes a document in full to a temporary file before replacing the old one, so a failed write never leaves a damaged document behind
+func (f *FileStore) Save(name string, data []byte) error {
+	err := os.MkdirAll(f.dir, 0755)
+	if err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(f.dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := tmpFile.Name()
+
+	_, err = tmpFile.Write(data)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, filepath.Join(f.dir, name))
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// Delete removes a document
+func (f *FileStore) Delete(name string) error {
+	return os.Remove(filepath.Join(f.dir, name))
+}
+
+// ModTime gives the time a document was last written
+func (f *FileStore) ModTime(name string) (time.Time, error) {
+	info, err := os.Stat(filepath.Join(f.dir, name))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// MemoryStore is a SaveStore that only lives as long as the program, for tests and when there is nowhere to write
+type MemoryStore struct {
+	docs  map[string][]byte
+	times map[string]time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{docs: map[string][]byte{}, times: map[string]time.Time{}}
+}
+
+// List gives the names of stored documents, in name order like a directory listing
+func (m *MemoryStore) List() ([]string, error) {
+	names := []string{}
+	for name := range m.docs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Load gives a copy of a stored document
+func (m *MemoryStore) Load(name string) ([]byte, error) {
+	data, ok := m.docs[name]
+	if !ok {
+		return nil, fmt.Errorf("load %s: %w", name, fs.ErrNotExist)
+	}
+	return append([]byte{}, data...), nil
+}
+
+// Save stores a copy of a document
+func (m *MemoryStore) Save(name string, data []byte) error {
+	m.docs[name] = append([]byte{}, data...)
+	m.times[name] = time.Now()
+	return nil
+}
+
+// Delete removes a stored document
+func (m *MemoryStore) Delete(name string) error {
+	if _, ok := m.docs[name]; !ok {
+		return fmt.Errorf("delete %s: %w", name, fs.ErrNotExist)
+	}
+	delete(m.docs, name)
+	delete(m.times, name)
+	return nil
+}
+
+// ModTime gives the time a document was last saved
+func (m *MemoryStore) ModTime(name string) (time.Time, error) {
+	t, ok := m.times[name]
+	if !ok {
+		return time.Time{}, fmt.Errorf("stat %s: %w", name, fs.ErrNotExist)
+	}
+	return t, nil
+}
+
+// storeHas reports whether a store holds a document, counting one that can't be checked as there so it is never overwritten
+func storeHas(store SaveStore, name string) bool {
+	_, err := store.ModTime(name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Error checking for %s: %v", name, err)
+	}
+	return !errors.Is(err, fs.ErrNotExist)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"testing"
+)
+
+func testSaveStore(t *testing.T, store SaveStore) {
+	names, err := store.List()
+	if err != nil || len(names) != 0 {
+		t.Fatalf("List on empty store = %v, %v", names, err)
+	}
+
+	if err := store.Save("slot2.json", []byte("two")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.Save("slot1.json", []byte("one")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.Save("slot1.json", []byte("uno")); err != nil {
+		t.Fatalf("Save over existing: %v", err)
+	}
+
+	names, err = store.List()
+	if err != nil || !reflect.DeepEqual(names, []string{"slot1.json", "slot2.json"}) {
+		t.Errorf("List = %v, %v", names, err)
+	}
+	data, err := store.Load("slot1.json")
+	if err != nil || string(data) != "uno" {
+		t.Errorf("Load = %q, %v, want uno", data, err)
+	}
+
+	if modTime, err := store.ModTime("slot1.json"); err != nil || modTime.IsZero() {
+		t.Errorf("ModTime = %v, %v", modTime, err)
+	}
+	if !storeHas(store, "slot2.json") || storeHas(store, "slot3.json") {
+		t.Errorf("storeHas slot2, slot3 = %v, %v, want true, false", storeHas(store, "slot2.json"), storeHas(store, "slot3.json"))
+	}
+
+	if err := store.Delete("slot2.json"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Load("slot2.json"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Load after Delete err = %v, want fs.ErrNotExist", err)
+	}
+	if _, err := store.ModTime("slot2.json"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ModTime after Delete err = %v, want fs.ErrNotExist", err)
+	}
+	if err := store.Delete("slot2.json"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("second Delete err = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testSaveStore(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	testSaveStore(t, NewFileStore(t.TempDir()+"/save"))
+}
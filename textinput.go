@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/tinne26/etxt"
+)
+
+const maxNameLength = 12 // fits the High Scores columns
+
+var (
+	errNameEmpty    = errors.New("Name can't be empty")
+	errNameReserved = errors.New("That name is reserved")
+	errNameChars    = errors.New("Use letters, numbers, spaces, - or _")
+
+	// names Windows won't accept as files, whatever the extension
+	reservedNames = []string{"CON", "PRN", "AUX", "NUL",
+		"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+		"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9"}
+)
+
+// TextInput is a Game State that edits a line of text, then hands it to done; Escape returns to the state beneath
+type TextInput struct {
+	stateHooks
+	prompt   string
+	text     []rune
+	cursor   int
+	maxLen   int
+	validate func(text string) error
+	done     func(g *Game, text string) error
+	err      error
+}
+
+// NewTextInput creates a TextInput holding text, with the cursor at its end
+func NewTextInput(prompt, text string, done func(g *Game, text string) error) *TextInput {
+	input := &TextInput{
+		prompt:   prompt,
+		text:     []rune(text),
+		maxLen:   maxNameLength,
+		validate: validName,
+		done:     done,
+	}
+	input.cursor = len(input.text)
+	return input
+}
+
+// nameRune reports whether r may be typed into a name
+func nameRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == ' ' || r == '-' || r == '_')
+}
+
+// validName checks a name is safe to use anywhere a file name might be made from it
+func validName(name string) error {
+	trimmed := strings.TrimSpace(name)
+	if trimmed == "" {
+		return errNameEmpty
+	}
+	for _, r := range trimmed {
+		if !nameRune(r) {
+			return errNameChars
+		}
+	}
+	for _, reserved := range reservedNames {
+		if strings.EqualFold(trimmed, reserved) {
+			return errNameReserved
+		}
+	}
+	return nil
+}
+
+// Update types characters at the cursor, moves and deletes with the editing keys, and submits on Enter
+func (t *TextInput) Update(g *Game) error {
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if !nameRune(r) || len(t.text) >= t.maxLen {
+			continue
+		}
+		t.text = append(t.text[:t.cursor], append([]rune{r}, t.text[t.cursor:]...)...)
+		t.cursor++
+		t.err = nil
+	}
+
+	switch {
+	case repeatingKeyPressed(ebiten.KeyBackspace) && t.cursor > 0:
+		t.text = append(t.text[:t.cursor-1], t.text[t.cursor:]...)
+		t.cursor--
+		t.err = nil
+	case repeatingKeyPressed(ebiten.KeyDelete) && t.cursor < len(t.text):
+		t.text = append(t.text[:t.cursor], t.text[t.cursor+1:]...)
+		t.err = nil
+	case repeatingKeyPressed(ebiten.KeyArrowLeft) && t.cursor > 0:
+		t.cursor--
+	case repeatingKeyPressed(ebiten.KeyArrowRight) && t.cursor < len(t.text):
+		t.cursor++
+	case inpututil.IsKeyJustPressed(ebiten.KeyHome):
+		t.cursor = 0
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnd):
+		t.cursor = len(t.text)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape) || gamepadJustPressed(actionBack) || backPressed():
+		log.Printf("Text entry cancelled")
+		g.pop()
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter) || gamepadJustPressed(actionConfirm):
+		text := strings.TrimSpace(string(t.text))
+		if t.err = t.validate(text); t.err != nil {
+			return nil
+		}
+		log.Printf("Text entered: %s", text)
+		return t.done(g, text)
+	}
+	return nil
+}
+
+// Draw displays the prompt, the text in a box with a blinking cursor, and any validation error
+func (t *TextInput) Draw(screen *ebiten.Image, g *Game) {
+	g.txtRenderer.SetTarget(screen)
+	g.txtRenderer.SetAlign(etxt.YCenter, etxt.XCenter)
+	g.txtRenderer.SetColor(menuColorInactive)
+	g.txtRenderer.SetSizePx(32)
+	g.txtRenderer.Draw(t.prompt, winWidth/2, 120)
+
+	boxX, boxY, boxW, boxH := 120, 200, winWidth-240, 50
+	ebitenutil.DrawRect(screen, float64(boxX), float64(boxY), float64(boxW), float64(boxH), menuColorInactive)
+	ebitenutil.DrawRect(screen, float64(boxX+2), float64(boxY+2), float64(boxW-4), float64(boxH-4), menuColorDisabled)
+
+	g.txtRenderer.SetSizePx(28)
+	g.txtRenderer.SetAlign(etxt.YCenter, etxt.Left)
+	g.txtRenderer.Draw(string(t.text), boxX+12, boxY+boxH/2)
+	if (g.count/30)%2 == 0 {
+		cursorX := boxX + 12 + g.txtRenderer.SelectionRect(string(t.text[:t.cursor])).Width.Ceil()
+		ebitenutil.DrawRect(screen, float64(cursorX), float64(boxY+10), 2, float64(boxH-20), menuColorActive)
+	}
+
+	g.txtRenderer.SetAlign(etxt.YCenter, etxt.XCenter)
+	g.txtRenderer.SetSizePx(15)
+	if t.err != nil {
+		g.txtRenderer.SetColor(menuColorActive)
+		g.txtRenderer.Draw(t.err.Error(), winWidth/2, boxY+boxH+30)
+		g.txtRenderer.SetColor(menuColorInactive)
+	}
+	g.txtRenderer.Draw("Enter / A: OK   Esc / B: Cancel", winWidth/2, boxY+boxH+70)
+	g.txtRenderer.SetSizePx(32)
+}
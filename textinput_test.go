@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestValidName(t *testing.T) {
+	tests := []struct {
+		name string
+		want error
+	}{
+		{"Mona", nil},
+		{"Space Cadet-2_b", nil},
+		{"   ", errNameEmpty},
+		{"../etc", errNameChars},
+		{"a/b", errNameChars},
+		{"Zoë", errNameChars},
+		{"con", errNameReserved},
+		{"LPT1", errNameReserved},
+	}
+	for _, tt := range tests {
+		if got := validName(tt.name); got != tt.want {
+			t.Errorf("validName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
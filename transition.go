@@ -0,0 +1,200 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// transitionType is an occasion for changing states, each with its own TransitionStyle
+type transitionType int
+
+const (
+	transitionToTitle transitionType = iota
+	transitionToWorld
+	transitionEnterLevel
+	transitionLeaveLevel
+	transitionLoseLife
+)
+
+type transitionEffect int
+
+const (
+	effectCut transitionEffect = iota
+	effectFade
+	effectIris  // closes to black around the player, then opens around the player in the new state
+	effectSlide // new state pushes the old one off to the left
+)
+
+const irisMaskRadius = 128
+
+var (
+	// transitionStyles sets the effect and length in ticks for each transitionType
+	transitionStyles = map[transitionType]TransitionStyle{
+		transitionToTitle:    {effectSlide, 30},
+		transitionToWorld:    {effectFade, 40},
+		transitionEnterLevel: {effectIris, 50},
+		transitionLeaveLevel: {effectIris, 50},
+		transitionLoseLife:   {effectFade, 60},
+	}
+
+	irisMask *ebiten.Image
+)
+
+// TransitionStyle is how a transition looks: its effect and how many ticks it lasts
+type TransitionStyle struct {
+	effect   transitionEffect
+	duration int
+}
+
+// Transition swaps states partway through an effect; state updates, and so input, wait until it is done
+type Transition struct {
+	TransitionStyle
+	swap    func()
+	elapsed int
+	swapped bool
+	from    *ebiten.Image // last frame before the swap, for slides
+	to      *ebiten.Image
+}
+
+// transitionTo changes states with swap, using the style set for kind
+func (g *Game) transitionTo(kind transitionType, swap func()) {
+	style := transitionStyles[kind]
+	if g.transition != nil || style.effect == effectCut || style.duration <= 0 {
+		swap()
+		return
+	}
+	g.transition = &Transition{TransitionStyle: style, swap: swap}
+}
+
+// swapAt is the tick when the states change: right away for a slide, once the screen is covered for fade and iris
+func (t *Transition) swapAt() int {
+	if t.effect == effectSlide {
+		return 0
+	}
+	return t.duration / 2
+}
+
+// update advances the transition, swapping states when it is time, and reports whether it is finished
+func (t *Transition) update() bool {
+	if t.effect == effectSlide && t.from == nil {
+		return false // wait for Draw to capture the old frame
+	}
+	if !t.swapped && t.elapsed >= t.swapAt() {
+		t.swap()
+		t.swapped = true
+	}
+	t.elapsed++
+	return t.elapsed > t.duration
+}
+
+// cover is how much of the screen is hidden, rising from 0 to 1 before the swap and falling back after it
+func (t *Transition) cover() float64 {
+	half := float64(t.duration) / 2
+	e := float64(t.elapsed)
+	if e <= half {
+		return e / half
+	}
+	return math.Max(0, (float64(t.duration)-e)/half)
+}
+
+// drawStates draws the top state, and the states beneath it when it is an overlay
+func (g *Game) drawStates(screen *ebiten.Image) {
+	bottom := len(g.states) - 1
+	for bottom > 0 && g.states[bottom].Overlay() {
+		bottom--
+	}
+	for _, s := range g.states[bottom:] {
+		s.Draw(screen, g)
+	}
+}
+
+// drawTransition draws the states as the running transition shows them
+func (g *Game) drawTransition(screen *ebiten.Image) {
+	t := g.transition
+	switch t.effect {
+	case effectSlide:
+		if t.from == nil {
+			t.from = ebiten.NewImage(winWidth, winHeight)
+			g.drawStates(t.from)
+		}
+		if !t.swapped {
+			screen.DrawImage(t.from, nil)
+			return
+		}
+		if t.to == nil {
+			t.to = ebiten.NewImage(winWidth, winHeight)
+		}
+		t.to.Clear()
+		g.drawStates(t.to)
+		shift := float64(winWidth) * float64(t.elapsed) / float64(t.duration)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(-shift, 0)
+		screen.DrawImage(t.from, op)
+		op = &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(winWidth)-shift, 0)
+		screen.DrawImage(t.to, op)
+	case effectFade:
+		g.drawStates(screen)
+		ebitenutil.DrawRect(screen, 0, 0, winWidth, winHeight, color.RGBA{0, 0, 0, uint8(255 * t.cover())})
+	case effectIris:
+		g.drawStates(screen)
+		cx, cy := irisCenter(g)
+		far := math.Hypot(math.Max(cx, winWidth-cx), math.Max(cy, winHeight-cy))
+		drawIris(screen, cx, cy, far*(1-t.cover()))
+	default:
+		g.drawStates(screen)
+	}
+}
+
+// irisCenter is the middle of the player in a level, the avatar on the World, or else the middle of the screen
+func irisCenter(g *Game) (float64, float64) {
+	if play, ok := findState[*Play](g); ok {
+		pc := play.level.Player
+		return float64(pc.X + playerCharWidth/2), float64(pc.Y + playerCharHeight/2)
+	}
+	if _, ok := findState[*World](g); ok && g.session != nil {
+		avatar := g.session.avatar
+		return float64(avatar.xCoord + worldCharWidth/2), float64(avatar.yCoord + worldCharHeight/2)
+	}
+	return winWidth / 2, winHeight / 2
+}
+
+// drawIris blacks out the screen except for a circle of radius r around (cx, cy)
+func drawIris(screen *ebiten.Image, cx, cy, r float64) {
+	if r < 1 {
+		ebitenutil.DrawRect(screen, 0, 0, winWidth, winHeight, color.Black)
+		return
+	}
+	if irisMask == nil {
+		irisMask = newIrisMask()
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(r/irisMaskRadius, r/irisMaskRadius)
+	op.GeoM.Translate(cx-r, cy-r)
+	screen.DrawImage(irisMask, op)
+
+	ebitenutil.DrawRect(screen, 0, 0, winWidth, cy-r, color.Black)
+	ebitenutil.DrawRect(screen, 0, cy+r, winWidth, winHeight-cy-r, color.Black)
+	ebitenutil.DrawRect(screen, 0, cy-r, cx-r, 2*r, color.Black)
+	ebitenutil.DrawRect(screen, cx+r, cy-r, winWidth-cx-r, 2*r, color.Black)
+}
+
+// newIrisMask creates a square that is black outside a clear circle touching its edges
+func newIrisMask() *ebiten.Image {
+	log.Printf("Creating iris mask")
+	size := irisMaskRadius * 2
+	mask := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			d := math.Hypot(float64(x)+0.5-irisMaskRadius, float64(y)+0.5-irisMaskRadius)
+			alpha := math.Min(1, math.Max(0, d-irisMaskRadius+1))
+			mask.Set(x, y, color.RGBA{0, 0, 0, uint8(255 * alpha)})
+		}
+	}
+	return ebiten.NewImageFromImage(mask)
+}
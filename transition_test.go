@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestFadeSwapsWhenScreenIsCovered(t *testing.T) {
+	swaps := 0
+	tr := &Transition{TransitionStyle: TransitionStyle{effectFade, 10}, swap: func() { swaps++ }}
+
+	for i := 0; i < 5; i++ {
+		if tr.update() {
+			t.Fatalf("finished after %d ticks", i+1)
+		}
+		if swaps != 0 {
+			t.Fatalf("swapped on tick %d, before the screen was covered", i+1)
+		}
+	}
+	if tr.cover() != 1 {
+		t.Errorf("cover at the swap = %v, want 1", tr.cover())
+	}
+	done := false
+	for i := 0; i < 6 && !done; i++ {
+		done = tr.update()
+	}
+	if !done || swaps != 1 || tr.cover() != 0 {
+		t.Errorf("after the fade: done %v, swaps %d, cover %v", done, swaps, tr.cover())
+	}
+}
+
+func TestSlideWaitsForOldFrame(t *testing.T) {
+	swapped := false
+	tr := &Transition{TransitionStyle: TransitionStyle{effectSlide, 10}, swap: func() { swapped = true }}
+	tr.update()
+	if swapped {
+		t.Error("slide swapped before the old frame was captured")
+	}
+}
+
+func TestCutTransitionSwapsRightAway(t *testing.T) {
+	saved := transitionStyles[transitionToTitle]
+	defer func() { transitionStyles[transitionToTitle] = saved }()
+	transitionStyles[transitionToTitle] = TransitionStyle{effectCut, 0}
+
+	g := &Game{}
+	swapped := false
+	g.transitionTo(transitionToTitle, func() { swapped = true })
+	if !swapped || g.transition != nil {
+		t.Errorf("cut: swapped %v, transition %v", swapped, g.transition)
+	}
+}
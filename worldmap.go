@@ -0,0 +1,165 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"image"
+	"log"
+	"math"
+)
+
+var (
+	walkSpeed  = 5.0
+	pathDotGap = 12
+)
+
+// WorldMap describes the nodes (levels and waypoints) on the World and the authored paths between them
+type WorldMap struct {
+	Navigation string // "path" walks between nodes, anything else roams freely within radius
+	Start      string
+	Nodes      []*MapNode
+	Paths      []*MapPath
+}
+
+// MapNode is a stopping point on the World, optionally tied to a level, in World image coordinates
+type MapNode struct {
+	Name  string
+	Level string
+	X     int
+	Y     int
+}
+
+// MapPath connects two MapNodes through a series of points, and is hidden until its From node is cleared
+type MapPath struct {
+	From     string
+	To       string
+	Points   [][2]int
+	revealed bool
+}
+
+func loadWorldMap(fs embed.FS, file string) *WorldMap {
+	worldMap := &WorldMap{}
+	mapContent, err := fs.ReadFile(file)
+	if err != nil {
+		log.Fatal("Error when opening file: ", err)
+	}
+
+	err = json.Unmarshal(mapContent, worldMap)
+	if err != nil {
+		log.Fatal("Error during Unmarshalling: ", err)
+	}
+	return worldMap
+}
+
+func (m *WorldMap) pathMode() bool {
+	return m != nil && m.Navigation == "path"
+}
+
+func (m *WorldMap) node(name string) *MapNode {
+	for _, n := range m.Nodes {
+		if n.Name == name {
+			return n
+		}
+	}
+	return nil
+}
+
+func (m *WorldMap) nearestNode(x, y int) *MapNode {
+	var nearest *MapNode
+	best := math.MaxFloat64
+	for _, n := range m.Nodes {
+		d := math.Hypot(float64(n.X-x), float64(n.Y-y))
+		if d < best {
+			best = d
+			nearest = n
+		}
+	}
+	return nearest
+}
+
+// reveal marks which paths are walkable: paths leading out of the start node, waypoints and completed levels
+func (m *WorldMap) reveal(levels []*LevelData) {
+	complete := map[string]bool{}
+	for _, l := range levels {
+		complete[l.Name] = l.Complete
+	}
+	for _, p := range m.Paths {
+		p.revealed = false
+	}
+
+	visited := map[string]bool{}
+	queue := []string{m.Start}
+	for len(queue) > 0 {
+		curr := queue[0]
+		queue = queue[1:]
+		if visited[curr] {
+			continue
+		}
+		visited[curr] = true
+
+		n := m.node(curr)
+		if n == nil || (n.Level != "" && !complete[n.Level]) {
+			continue
+		}
+		for _, p := range m.Paths {
+			if p.From == curr {
+				p.revealed = true
+				queue = append(queue, p.To)
+			}
+		}
+	}
+}
+
+// route finds a revealed path leaving the named node in the given direction, returning its points and destination
+func (m *WorldMap) route(from string, direction string) ([]image.Point, string) {
+	for _, p := range m.Paths {
+		if !p.revealed || len(p.Points) < 2 {
+			continue
+		}
+		var points []image.Point
+		var dest string
+		switch from {
+		case p.From:
+			for _, pt := range p.Points {
+				points = append(points, image.Pt(pt[0], pt[1]))
+			}
+			dest = p.To
+		case p.To:
+			for i := len(p.Points) - 1; i >= 0; i-- {
+				points = append(points, image.Pt(p.Points[i][0], p.Points[i][1]))
+			}
+			dest = p.From
+		default:
+			continue
+		}
+		if heading(points[0], points[1]) == direction {
+			return points[1:], dest
+		}
+	}
+	return nil, ""
+}
+
+// heading gives the dominant direction of travel between two points
+func heading(a, b image.Point) string {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	switch {
+	case math.Abs(float64(dx)) >= math.Abs(float64(dy)) && dx >= 0:
+		return "right"
+	case math.Abs(float64(dx)) >= math.Abs(float64(dy)):
+		return "left"
+	case dy < 0:
+		return "up"
+	default:
+		return "down"
+	}
+}
+
+func clamp(v, lo, hi int) int {
+	switch {
+	case v < lo:
+		return lo
+	case v > hi:
+		return hi
+	}
+	return v
+}